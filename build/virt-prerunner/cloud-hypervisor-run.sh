@@ -1,3 +1,6 @@
 #!/usr/bin/execlineb -P
 
-cloud-hypervisor --api-socket /var/run/virtink/ch.sock
+# the log is also kept for virt-daemon to detect watchdog expiry, which Cloud Hypervisor reports nowhere else
+pipeline -w { tee -a /var/run/virtink/ch.log }
+fdmove -c 2 1
+cloud-hypervisor --api-socket /var/run/virtink/ch.sock --event-monitor path=/var/run/virtink/ch-events.json
@@ -9,18 +9,36 @@ case $1 in
     temp=$(mktemp -d)
     echo "$2" | base64 -d > $temp/meta-data
 
-    if [[ "$3" =~ ^/.* ]]; then
-      cp $3 $temp/user-data
-    else
-      echo "$3" | base64 -d > $temp/user-data
+    case "$3" in
+      /*) cp $3 $temp/user-data ;;
+      *) echo "$3" | base64 -d > $temp/user-data ;;
+    esac
+
+    case "$4" in
+      /*) cp $4 $temp/network-config ;;
+      *) echo "$4" | base64 -d > $temp/network-config ;;
+    esac
+
+    genisoimage -volid cidata -joliet -rock -output $5 $temp
+    ;;
+  "import")
+    # virt-init-volume import <url or path> <checksum> <target>
+    source=$2
+    case "$2" in
+      http://*|https://*)
+        source=${IMPORT_SCRATCH_DIR:-/var/lib/virtink/import}/disk
+        curl -fsSL --retry 3 -o $source "$2"
+        ;;
+    esac
+
+    if [ -n "$3" ]; then
+      echo "${3#*:}  $source" | ${3%%:*}sum -c
     fi
 
-    if [[ "$4" =~ ^/.* ]]; then
-      cp $4 $temp/network-config
+    if [ -b $4 ]; then
+      qemu-img convert -n -O raw $source $4
     else
-      echo "$4" | base64 -d > $temp/network-config
+      qemu-img convert -O raw $source $4
     fi
-
-    genisoimage -volid cidata -joliet -rock -output $5 $temp
     ;;
 esac
@@ -4,4 +4,48 @@ set -o errexit
 set -o nounset
 set -o pipefail
 
-qemu-img convert /disk $1
+# wait_for_image_cache waits for virt-daemon to look the image up in the image cache, and returns
+# whether the image is cached. Images are copied if virt-daemon doesn't respond in time.
+wait_for_image_cache() {
+  for i in $(seq 30); do
+    if [ -f $1/image-cache-entry ]; then
+      return 0
+    fi
+    if [ -f $1/image-cache-miss ]; then
+      return 1
+    fi
+    sleep 1
+  done
+  return 1
+}
+
+info=$(qemu-img info --output json /disk)
+format=$(echo "$info" | jq -r '.format')
+
+case "$1" in
+*.raw)
+  qemu-img convert -O raw /disk $1
+  ;;
+*)
+  # virt-daemon looks the image up in the image cache of the node, which is not accessible from
+  # init containers, and the disk is not copied if it's cached. Otherwise the disk is copied to the
+  # VM Pod and added to the cache by virt-daemon afterwards. The VM writes to a QCOW2 overlay backed
+  # by the disk, created by prerunner.
+  if wait_for_image_cache $(dirname $1); then
+    echo -n $format > /dev/termination-log
+    exit 0
+  fi
+
+  # Cloud Hypervisor doesn't support compressed clusters, extended L2 entries or external data
+  # files of QCOW2 images, so images using them, as well as images in other formats, are converted.
+  if [ "$format" = qcow2 ] && \
+    echo "$info" | jq -e '."backing-filename" == null and ."format-specific".data."refcount-bits" == 16 and ."format-specific".data."extended-l2" != true and ."format-specific".data."data-file" == null' > /dev/null && \
+    qemu-img map --output json /disk | jq -e 'all(.[]; .compressed != true)' > /dev/null; then
+    cp /disk $1
+  else
+    qemu-img convert -O qcow2 /disk $1
+  fi
+  ;;
+esac
+
+echo -n $format > /dev/termination-log
@@ -4,4 +4,30 @@ set -o errexit
 set -o nounset
 set -o pipefail
 
+# wait_for_image_cache waits for virt-daemon to look the image up in the image cache, and returns
+# whether the image is cached. Images are copied if virt-daemon doesn't respond in time.
+wait_for_image_cache() {
+  for i in $(seq 30); do
+    if [ -f $1/image-cache-entry ]; then
+      return 0
+    fi
+    if [ -f $1/image-cache-miss ]; then
+      return 1
+    fi
+    sleep 1
+  done
+  return 1
+}
+
+# virt-daemon looks the image up in the image cache of the node, which is not accessible from init
+# containers, and the kernel and initrd are not copied if they're cached. Otherwise they're copied to
+# the VM Pod and added to the cache by virt-daemon afterwards.
+if wait_for_image_cache $(dirname $1); then
+  exit 0
+fi
+
 cp /vmlinux $1
+
+if [ -f /initrd ]; then
+  cp /initrd $(dirname $1)/initrd
+fi
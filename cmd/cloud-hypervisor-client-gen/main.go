@@ -120,6 +120,12 @@ func newEndpoint(path string, pathItem *openapi.PathItem) *endpoint {
 	return ep
 }
 
+// nullableFields are optional fields whose zero values are meaningful, which are generated as pointers so that zero
+// values are not omitted.
+var nullableFields = map[string]bool{
+	"MemoryZoneConfig.host_numa_node": true,
+}
+
 type type_ struct {
 	Name   string  `json:"name,omitempty"`
 	Desc   string  `json:"desc,omitempty"`
@@ -138,7 +144,11 @@ func newType(name string, schema *openapi.Schema) *type_ {
 				required = true
 			}
 		}
-		tp.Fields = append(tp.Fields, *newField(fieldName, fieldSchema, required))
+		f := newField(fieldName, fieldSchema, required)
+		if nullableFields[name+"."+fieldName] {
+			f.Type = "*" + f.Type
+		}
+		tp.Fields = append(tp.Fields, *f)
 	}
 	sort.Sort(fieldSorter(tp.Fields))
 	return tp
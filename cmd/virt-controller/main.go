@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	netv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,11 +40,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var hostDiskPathPrefixes string
+	var vhostUserSocketPathPrefixes string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&hostDiskPathPrefixes, "host-disk-path-prefixes", "", "Comma-separated node directories under which hostDisk volumes are allowed.")
+	flag.StringVar(&vhostUserSocketPathPrefixes, "vhost-user-socket-path-prefixes", "", "Comma-separated node directories under which vhost-user sockets of vhostUser volumes are allowed.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -78,7 +83,18 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "VMMutator")
 		os.Exit(1)
 	}
-	if err := (&controller.VMValidator{}).SetupWebhookWithManager(mgr); err != nil {
+	var hostDiskPathPrefixList []string
+	if hostDiskPathPrefixes != "" {
+		hostDiskPathPrefixList = strings.Split(hostDiskPathPrefixes, ",")
+	}
+	var vhostUserSocketPathPrefixList []string
+	if vhostUserSocketPathPrefixes != "" {
+		vhostUserSocketPathPrefixList = strings.Split(vhostUserSocketPathPrefixes, ",")
+	}
+	if err := (&controller.VMValidator{
+		HostDiskPathPrefixes:        hostDiskPathPrefixList,
+		VhostUserSocketPathPrefixes: vhostUserSocketPathPrefixList,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "VMValidator")
 		os.Exit(1)
 	}
@@ -97,6 +113,37 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controller.VMVMReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("virt-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VMVM")
+		os.Exit(1)
+	}
+
+	if err := (&controller.VMVMValidator{
+		Client: mgr.GetClient(),
+	}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "VMVMValidator")
+		os.Exit(1)
+	}
+
+	if err = (&controller.VIReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("virt-controller"),
+		PrerunnerImageName: os.Getenv("PRERUNNER_IMAGE"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VI")
+		os.Exit(1)
+	}
+
+	if err := (&controller.VIValidator{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "VIValidator")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
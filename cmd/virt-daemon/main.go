@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -15,6 +16,7 @@ import (
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
 	"github.com/smartxworks/virtink/pkg/daemon"
 	"github.com/smartxworks/virtink/pkg/daemon/deviceplugin"
+	"github.com/smartxworks/virtink/pkg/daemon/imagecache"
 	"github.com/smartxworks/virtink/pkg/daemon/tcpproxy"
 )
 
@@ -32,8 +34,10 @@ func init() {
 func main() {
 	var metricsAddr string
 	var probeAddr string
+	var imageCacheSizeLimit string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&imageCacheSizeLimit, "image-cache-size-limit", "20Gi", "The size beyond which unused kernels and containerDisk images are evicted from the node image cache.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -52,13 +56,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	imageCachePopulator := imagecache.NewPopulator(imagecache.DefaultDir)
+	if err = imageCachePopulator.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create image cache populator")
+		os.Exit(1)
+	}
+
 	if err = (&daemon.VMReconciler{
-		Client:        mgr.GetClient(),
-		Scheme:        mgr.GetScheme(),
-		Recorder:      mgr.GetEventRecorderFor("virt-daemon"),
-		NodeName:      os.Getenv("NODE_NAME"),
-		NodeIP:        os.Getenv("NODE_IP"),
-		RelayProvider: tcpproxy.NewRelayProvider(),
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		Recorder:            mgr.GetEventRecorderFor("virt-daemon"),
+		NodeName:            os.Getenv("NODE_NAME"),
+		NodeIP:              os.Getenv("NODE_IP"),
+		RelayProvider:       tcpproxy.NewRelayProvider(),
+		ImageCacheDir:       imagecache.DefaultDir,
+		ImageCachePopulator: imageCachePopulator,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "VM")
 		os.Exit(1)
@@ -69,6 +81,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	imageCacheSizeLimitQuantity, err := resource.ParseQuantity(imageCacheSizeLimit)
+	if err != nil {
+		setupLog.Error(err, "unable to parse image cache size limit")
+		os.Exit(1)
+	}
+	if err = (&imagecache.GarbageCollector{
+		Client:    mgr.GetClient(),
+		NodeName:  os.Getenv("NODE_NAME"),
+		Dir:       imagecache.DefaultDir,
+		SizeLimit: imageCacheSizeLimitQuantity.Value(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create image cache garbage collector")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
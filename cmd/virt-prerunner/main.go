@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/docker/docker/libnetwork/resolvconf"
 	"github.com/docker/docker/libnetwork/types"
@@ -22,10 +23,14 @@ import (
 	"github.com/namsral/flag"
 	"github.com/subgraph/libmacouflage"
 	"github.com/vishvananda/netlink"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
 	"github.com/smartxworks/virtink/pkg/cloudhypervisor"
 	"github.com/smartxworks/virtink/pkg/cpuset"
+	"github.com/smartxworks/virtink/pkg/daemon/imagecache"
+	"github.com/smartxworks/virtink/pkg/volumeutil"
 )
 
 func main() {
@@ -67,7 +72,15 @@ func main() {
 	log.Println("Succeeded to setup")
 }
 
+// getCPUSet returns the CPUs of the VM Pod, which are dedicated to the VM with DedicatedCPUPlacement
+var getCPUSet = cpuset.Get
+
+// kernelVolumePath is where the init container of the kernel image copies the kernel and the initrd
+var kernelVolumePath = "/mnt/virtink-kernel"
+
 func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloudhypervisor.VmConfig, error) {
+	threadsPerCore := vm.Spec.Instance.CPU.GetThreadsPerCore()
+
 	vmConfig := cloudhypervisor.VmConfig{
 		Console: &cloudhypervisor.ConsoleConfig{
 			Mode: "Pty",
@@ -79,14 +92,16 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 			Kernel: "/var/lib/cloud-hypervisor/hypervisor-fw",
 		},
 		Cpus: &cloudhypervisor.CpusConfig{
-			MaxVcpus:  int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket),
-			BootVcpus: int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket),
+			MaxVcpus:  int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket * threadsPerCore),
+			BootVcpus: int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket * threadsPerCore),
 			Topology: &cloudhypervisor.CpuTopology{
 				Packages:       int(vm.Spec.Instance.CPU.Sockets),
 				DiesPerPackage: 1,
 				CoresPerDie:    int(vm.Spec.Instance.CPU.CoresPerSocket),
-				ThreadsPerCore: 1,
+				ThreadsPerCore: int(threadsPerCore),
 			},
+			MaxPhysBits: vm.Spec.Instance.CPU.MaxPhysBits,
+			KvmHyperv:   vm.Spec.Instance.CPU.KVMHyperv,
 		},
 		Memory: &cloudhypervisor.MemoryConfig{
 			Size: vm.Spec.Instance.Memory.Size.Value(),
@@ -97,24 +112,64 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 		vmConfig.Payload.Kernel = "/var/lib/cloud-hypervisor/CLOUDHV_EFI.fd"
 	}
 
+	if vm.Spec.Instance.Firmware != nil {
+		switch {
+		case vm.Spec.Instance.Firmware.Image != "":
+			vmConfig.Payload.Kernel = "/mnt/virtink-firmware/firmware"
+		case vm.Spec.Instance.Firmware.Type == virtv1alpha1.FirmwareOVMF:
+			if runtime.GOARCH != "arm64" {
+				vmConfig.Payload.Kernel = "/var/lib/cloud-hypervisor/CLOUDHV.fd"
+			}
+		case vm.Spec.Instance.Firmware.Type == virtv1alpha1.FirmwareRustHypervisorFirmware:
+			if runtime.GOARCH == "arm64" {
+				return nil, fmt.Errorf("rust-hypervisor-firmware is not supported on arm64")
+			}
+		}
+	}
+
 	if vm.Spec.Instance.Kernel != nil {
-		vmConfig.Payload.Kernel = "/mnt/virtink-kernel/vmlinux"
+		kernelDir, err := useCachedKernel(kernelVolumePath)
+		if err != nil {
+			return nil, fmt.Errorf("use cached kernel: %s", err)
+		}
+		vmConfig.Payload.Kernel = filepath.Join(kernelDir, "vmlinux")
 		vmConfig.Payload.Cmdline = vm.Spec.Instance.Kernel.Cmdline
+
+		initrdPath := filepath.Join(kernelDir, "initrd")
+		if _, err := os.Stat(initrdPath); err == nil {
+			vmConfig.Payload.Initramfs = initrdPath
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("stat initrd: %s", err)
+		}
 	}
 
+	var pcpus []int
 	if vm.Spec.Instance.CPU.DedicatedCPUPlacement {
-		cpuSet, err := cpuset.Get()
+		cpuSet, err := getCPUSet()
 		if err != nil {
 			return nil, fmt.Errorf("get CPU set: %s", err)
 		}
 
-		pcpus := cpuSet.ToSlice()
-		numVCPUs := int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket)
-		if len(pcpus) != numVCPUs {
+		pcpus = cpuSet.ToSlice()
+		numVCPUs := int(vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket * threadsPerCore)
+		numPCPUs := numVCPUs
+		if vm.Spec.Instance.CPU.IsolateEmulatorThread {
+			numPCPUs++
+		}
+		if len(pcpus) != numPCPUs {
 			// TODO: report an event to object VM
 			return nil, fmt.Errorf("number of pCPUs and vCPUs must match")
 		}
 
+		if threadsPerCore > 1 {
+			pcpus, err = groupThreadSiblings(cpuSet)
+			if err != nil {
+				return nil, fmt.Errorf("group thread siblings: %s", err)
+			}
+		}
+
+		// the remaining pCPU, if any, is left for the emulator thread
+		pcpus = pcpus[:numVCPUs]
 		for i := 0; i < numVCPUs; i++ {
 			vmConfig.Cpus.Affinity = append(vmConfig.Cpus.Affinity, &cloudhypervisor.CpuAffinity{
 				Vcpu:     i,
@@ -123,8 +178,32 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 		}
 	}
 
+	for _, feature := range vm.Spec.Instance.CPU.Features {
+		if vmConfig.Cpus.Features == nil {
+			vmConfig.Cpus.Features = &cloudhypervisor.CpuFeatures{}
+		}
+		switch feature {
+		case virtv1alpha1.CPUFeatureAMX:
+			vmConfig.Cpus.Features.Amx = true
+		}
+	}
+
 	if vm.Spec.Instance.Memory.Hugepages != nil {
+		pageSize, err := resource.ParseQuantity(vm.Spec.Instance.Memory.Hugepages.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("parse hugepages page size: %s", err)
+		}
 		vmConfig.Memory.Hugepages = true
+		vmConfig.Memory.HugepageSize = pageSize.Value()
+	}
+
+	vmConfig.Memory.Thp = vm.Spec.Instance.Memory.TransparentHugepages == nil || *vm.Spec.Instance.Memory.TransparentHugepages
+	vmConfig.Memory.Mergeable = vm.Spec.Instance.Memory.Mergeable
+	vmConfig.Memory.Prefault = vm.Spec.Instance.Memory.Prefault
+
+	if vm.Spec.Instance.CPU.Realtime != nil {
+		// hugepages are never swapped out, so prefaulting them locks the whole guest memory in RAM
+		vmConfig.Memory.Prefault = true
 	}
 
 	blockVolumes := map[string]bool{}
@@ -132,48 +211,135 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 		blockVolumes[volume] = true
 	}
 
+	containerDiskPaths := map[string]string{}
+	for _, volume := range vm.Spec.Volumes {
+		if volume.ContainerDisk != nil {
+			isPmem := false
+			for _, pmem := range vm.Spec.Instance.PmemDevices {
+				if pmem.Name == volume.Name {
+					isPmem = true
+				}
+			}
+			if !isPmem {
+				diskPath, err := useCachedContainerDisk(fmt.Sprintf("/mnt/%s", volume.Name))
+				if err != nil {
+					return nil, fmt.Errorf("use cached disk of volume %q: %s", volume.Name, err)
+				}
+				containerDiskPaths[volume.Name] = diskPath
+			}
+		}
+		if volume.EmptyDisk != nil {
+			if err := createEmptyDisk(fmt.Sprintf("/mnt/%s/disk.raw", volume.Name), volume.EmptyDisk.Capacity.Value()); err != nil {
+				return nil, fmt.Errorf("create empty disk %q: %s", volume.Name, err)
+			}
+		}
+		if isKubernetesObjectVolume(&volume) {
+			isUsedByDisk := false
+			for _, disk := range vm.Spec.Instance.Disks {
+				if disk.Name == volume.Name {
+					isUsedByDisk = true
+					break
+				}
+			}
+			if isUsedByDisk {
+				if err := createISO(fmt.Sprintf("/mnt/%s", volume.Name), volume.Name, getKubernetesObjectVolumeISOPath(&volume)); err != nil {
+					return nil, fmt.Errorf("create ISO of volume %q: %s", volume.Name, err)
+				}
+			}
+		}
+		if volume.HostDisk != nil && volume.HostDisk.Type == virtv1alpha1.HostDiskExistsOrCreate {
+			if err := growEmptyHostDisk(fmt.Sprintf("/mnt/%s/disk.img", volume.Name), volume.HostDisk.Capacity.Value()); err != nil {
+				return nil, fmt.Errorf("create host disk %q: %s", volume.Name, err)
+			}
+		}
+	}
+
+	for _, group := range vm.Spec.Instance.DiskIOLimitGroups {
+		vmConfig.RateLimitGroups = append(vmConfig.RateLimitGroups, &cloudhypervisor.RateLimitGroupConfig{
+			Id:                group.Name,
+			RateLimiterConfig: cloudhypervisor.NewDiskRateLimiterConfig(&group.DiskIOLimits),
+		})
+	}
+
 	for _, disk := range vm.Spec.Instance.Disks {
 		for _, volume := range vm.Spec.Volumes {
 			if volume.Name == disk.Name {
 				diskConfig := cloudhypervisor.DiskConfig{
-					Id:     disk.Name,
-					Direct: true,
+					Id:        disk.Name,
+					Direct:    disk.Cache != virtv1alpha1.DiskCacheWriteback,
+					NumQueues: disk.NumQueues,
+					QueueSize: disk.QueueSize,
+					Serial:    disk.GetSerial(),
 				}
-				switch {
-				case volume.ContainerDisk != nil:
-					diskConfig.Path = fmt.Sprintf("/mnt/%s/disk.raw", volume.Name)
-				case volume.CloudInit != nil:
-					diskConfig.Path = fmt.Sprintf("/mnt/%s/cloud-init.iso", volume.Name)
-				case volume.ContainerRootfs != nil:
-					diskConfig.Path = fmt.Sprintf("/mnt/%s/rootfs.raw", volume.Name)
-				case volume.PersistentVolumeClaim != nil, volume.DataVolume != nil:
-					if blockVolumes[volume.Name] {
-						if volume.IsHotpluggable() {
-							diskConfig.Path = fmt.Sprintf("/hotplug-volumes/%s", volume.Name)
-						} else {
-							diskConfig.Path = fmt.Sprintf("/mnt/%s", volume.Name)
-						}
-					} else {
-						if volume.IsHotpluggable() {
-							diskConfig.Path = filepath.Join("/hotplug-volumes", fmt.Sprintf("%s.img", volume.Name))
-						} else {
-							diskConfig.Path = filepath.Join("/mnt", volume.Name, "disk.img")
-						}
+				diskPath, ok := containerDiskPaths[volume.Name]
+				if !ok {
+					var err error
+					diskPath, err = getVolumeDiskPath(&volume, blockVolumes[volume.Name])
+					if err != nil {
+						return nil, err
 					}
-				default:
-					return nil, fmt.Errorf("invalid source of volume %q", volume.Name)
+				}
+				if volume.VhostUser != nil {
+					// vhost-user backends access guest memory directly
+					vmConfig.Memory.Shared = true
+					diskConfig.Direct = false
+					diskConfig.VhostUser = true
+					diskConfig.VhostSocket = diskPath
+				} else {
+					diskConfig.Path = diskPath
 				}
 
-				if disk.ReadOnly != nil && *disk.ReadOnly {
+				if disk.ReadOnly != nil && *disk.ReadOnly || isKubernetesObjectVolume(&volume) {
 					diskConfig.Readonly = true
 				}
 
+				if disk.IOLimits != nil {
+					diskConfig.RateLimiterConfig = cloudhypervisor.NewDiskRateLimiterConfig(disk.IOLimits)
+				}
+				diskConfig.RateLimitGroup = disk.IOLimitGroup
+
+				if len(pcpus) > 0 {
+					for i := 0; i < disk.NumQueues; i++ {
+						diskConfig.QueueAffinity = append(diskConfig.QueueAffinity, &cloudhypervisor.VirtQueueAffinity{
+							QueueIndex: i,
+							HostCpus:   []int{pcpus[i%len(pcpus)]},
+						})
+					}
+				}
+
 				vmConfig.Disks = append(vmConfig.Disks, &diskConfig)
 				break
 			}
 		}
 	}
 
+	for _, pmem := range vm.Spec.Instance.PmemDevices {
+		for _, volume := range vm.Spec.Volumes {
+			if volume.Name == pmem.Name {
+				pmemPath, err := getVolumeDiskPath(&volume, blockVolumes[volume.Name])
+				if err != nil {
+					return nil, err
+				}
+				pmemSize, err := volumeutil.GetDiskSize(pmemPath)
+				if err != nil {
+					return nil, fmt.Errorf("get size of volume %q: %s", volume.Name, err)
+				}
+				if pmemSize%(2<<20) != 0 {
+					return nil, fmt.Errorf("size of volume %q is not a multiple of 2MiB", volume.Name)
+				}
+
+				pmemConfig := cloudhypervisor.PmemConfig{
+					Id:            pmem.Name,
+					File:          pmemPath,
+					Size:          pmemSize,
+					DiscardWrites: pmem.DiscardWrites,
+				}
+				vmConfig.Pmem = append(vmConfig.Pmem, &pmemConfig)
+				break
+			}
+		}
+	}
+
 	for _, fs := range vm.Spec.Instance.FileSystems {
 		vmConfig.Memory.Shared = true
 
@@ -201,6 +367,49 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 		}
 	}
 
+	if vm.Spec.Instance.TPM != nil {
+		if err := os.MkdirAll("/var/lib/virtink/tpm", 0755); err != nil {
+			return nil, fmt.Errorf("create TPM state dir: %s", err)
+		}
+
+		socketPath := "/var/run/virtink/swtpm.sock"
+		if err := startSWTPM("/var/lib/virtink/tpm", socketPath); err != nil {
+			return nil, fmt.Errorf("start swtpm: %s", err)
+		}
+		vmConfig.Tpm = &cloudhypervisor.TpmConfig{
+			Socket: socketPath,
+		}
+	}
+
+	if vm.Spec.Instance.Watchdog != nil {
+		vmConfig.Watchdog = true
+	}
+
+	if vm.Spec.Instance.Pvpanic != nil {
+		vmConfig.Pvpanic = true
+	}
+
+	if vm.Spec.Instance.RNG != nil {
+		source := vm.Spec.Instance.RNG.Source
+		if source == "" {
+			source = "/dev/urandom"
+		}
+		vmConfig.Rng = &cloudhypervisor.RngConfig{
+			Src: source,
+		}
+	}
+
+	if vm.Spec.Instance.Platform != nil {
+		vmConfig.Platform = &cloudhypervisor.PlatformConfig{
+			Uuid:         vm.Spec.Instance.Platform.UUID,
+			SerialNumber: vm.Spec.Instance.Platform.SerialNumber,
+			OemStrings:   vm.Spec.Instance.Platform.OEMStrings,
+		}
+		if vmConfig.Platform.Uuid == "" {
+			vmConfig.Platform.Uuid = string(vm.UID)
+		}
+	}
+
 	networkStatusList := []netv1.NetworkStatus{}
 	if os.Getenv("NETWORK_STATUS") != "" {
 		if err := json.Unmarshal([]byte(os.Getenv("NETWORK_STATUS")), &networkStatusList); err != nil {
@@ -227,20 +436,26 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 			switch {
 			case iface.Bridge != nil:
 				netConfig := cloudhypervisor.NetConfig{
-					Id: iface.Name,
+					Id:        iface.Name,
+					NumQueues: iface.NumQueues,
+					QueueSize: iface.QueueSize,
 				}
 				if err := setupBridgeNetwork(linkName, fmt.Sprintf("169.254.%d.1/30", 200+networkIndex), &netConfig); err != nil {
 					return nil, fmt.Errorf("setup bridge network: %s", err)
 				}
+				netConfig.RateLimiterConfig = buildNetRateLimiterConfig(vm, &iface, &network)
 				vmConfig.Net = append(vmConfig.Net, &netConfig)
 			case iface.Masquerade != nil:
 				netConfig := cloudhypervisor.NetConfig{
-					Id:  iface.Name,
-					Mac: iface.MAC,
+					Id:        iface.Name,
+					Mac:       iface.MAC,
+					NumQueues: iface.NumQueues,
+					QueueSize: iface.QueueSize,
 				}
 				if err := setupMasqueradeNetwork(linkName, iface.Masquerade.CIDR, &netConfig); err != nil {
 					return nil, fmt.Errorf("setup masquerade network: %s", err)
 				}
+				netConfig.RateLimiterConfig = buildNetRateLimiterConfig(vm, &iface, &network)
 				vmConfig.Net = append(vmConfig.Net, &netConfig)
 			case iface.SRIOV != nil:
 				for _, networkStatus := range networkStatusList {
@@ -270,6 +485,8 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 					Mac:       iface.MAC,
 					VhostUser: true,
 					VhostMode: "Server",
+					NumQueues: iface.NumQueues,
+					QueueSize: iface.QueueSize,
 				}
 				if err := setupVhostUserNetwork(linkName, &netConfig); err != nil {
 					return nil, fmt.Errorf("setup vhost-user network: %s", err)
@@ -280,9 +497,304 @@ func buildVMConfig(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (*cloud
 		}
 	}
 
+	if vm.Spec.Instance.NUMA != nil {
+		if err := buildNUMAConfig(vm, pcpus, &vmConfig); err != nil {
+			return nil, fmt.Errorf("build NUMA config: %s", err)
+		}
+	}
+
 	return &vmConfig, nil
 }
 
+func getVolumeDiskPath(volume *virtv1alpha1.Volume, isBlock bool) (string, error) {
+	switch {
+	case volume.ContainerDisk != nil:
+		// disks of containerDisk volumes other than pmem devices are located by useCachedContainerDisk
+		return fmt.Sprintf("/mnt/%s/disk.raw", volume.Name), nil
+	case volume.CloudInit != nil:
+		return fmt.Sprintf("/mnt/%s/cloud-init.iso", volume.Name), nil
+	case volume.EmptyDisk != nil:
+		return fmt.Sprintf("/mnt/%s/disk.raw", volume.Name), nil
+	case volume.HostDisk != nil:
+		return fmt.Sprintf("/mnt/%s/disk.img", volume.Name), nil
+	case isKubernetesObjectVolume(volume):
+		return getKubernetesObjectVolumeISOPath(volume), nil
+	case volume.ContainerRootfs != nil:
+		return fmt.Sprintf("/mnt/%s/rootfs.raw", volume.Name), nil
+	case volume.PersistentVolumeClaim != nil, volume.DataVolume != nil:
+		if isBlock {
+			if volume.IsHotpluggable() {
+				return fmt.Sprintf("/hotplug-volumes/%s", volume.Name), nil
+			}
+			return fmt.Sprintf("/mnt/%s", volume.Name), nil
+		}
+		if volume.IsHotpluggable() {
+			return filepath.Join("/hotplug-volumes", fmt.Sprintf("%s.img", volume.Name)), nil
+		}
+		return filepath.Join("/mnt", volume.Name, "disk.img"), nil
+	case volume.VhostUser != nil:
+		if volume.VhostUser.HostPath != "" {
+			return filepath.Join("/mnt", volume.Name, filepath.Base(volume.VhostUser.HostPath)), nil
+		}
+		return filepath.Join("/mnt", volume.Name, volume.VhostUser.SocketName), nil
+	default:
+		return "", fmt.Errorf("invalid source of volume %q", volume.Name)
+	}
+}
+
+func createEmptyDisk(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	return f.Truncate(size)
+}
+
+func isKubernetesObjectVolume(volume *virtv1alpha1.Volume) bool {
+	return volume.ConfigMap != nil || volume.Secret != nil || volume.ServiceAccount != nil || volume.DownwardAPI != nil
+}
+
+func getKubernetesObjectVolumeISOPath(volume *virtv1alpha1.Volume) string {
+	return fmt.Sprintf("/var/run/virtink/iso/%s.iso", volume.Name)
+}
+
+// createISO creates an ISO image of the files in dir. Files in Kubernetes object volumes are symlinks to hidden
+// timestamped directories, which are followed and excluded respectively.
+func createISO(dir string, volumeID string, isoPath string) error {
+	if err := os.MkdirAll(filepath.Dir(isoPath), 0755); err != nil {
+		return err
+	}
+	// ISO 9660 volume IDs are limited to 32 characters
+	if len(volumeID) > 32 {
+		volumeID = volumeID[:32]
+	}
+	_, err := executeCommand("genisoimage", "-volid", volumeID, "-joliet", "-rock", "-follow-links", "-m", "..*", "-output", isoPath, dir)
+	return err
+}
+
+// readImageCacheEntry returns the path of the image cache entry written by virt-daemon to dir, or an empty string if
+// the image is not cached.
+func readImageCacheEntry(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, imagecache.EntryFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// useCachedKernel returns the directory of the kernel and initrd, which is the image cache entry if the kernel is
+// cached, or dir with the copies made by the init container otherwise.
+func useCachedKernel(dir string) (string, error) {
+	entryPath, err := readImageCacheEntry(dir)
+	if err != nil {
+		return "", fmt.Errorf("read image cache entry: %s", err)
+	}
+	if entryPath == "" {
+		return dir, nil
+	}
+	for _, name := range []string{"vmlinux", "initrd"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return entryPath, nil
+}
+
+// useCachedContainerDisk returns the path of the disk of a containerDisk volume. The VM writes to a QCOW2 overlay,
+// backed by the disk in the image cache if the disk is cached, or by the copy made by the init container otherwise,
+// which is left intact for virt-daemon to add to the cache. Disks of images based on released versions of
+// virtink-container-disk-base are converted to disk.qcow2 in raw format, which are used privately as is.
+func useCachedContainerDisk(dir string) (string, error) {
+	overlayPath := filepath.Join(dir, "overlay.qcow2")
+	if _, err := os.Stat(overlayPath); err == nil {
+		// created by the previous run
+		return overlayPath, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	copyPath := filepath.Join(dir, "disk.qcow2")
+	isCopied := true
+	isQCOW2, err := imagecache.IsQCOW2(copyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		isCopied = false
+	} else if !isQCOW2 {
+		return copyPath, nil
+	}
+
+	entryPath, err := readImageCacheEntry(dir)
+	if err != nil {
+		return "", fmt.Errorf("read image cache entry: %s", err)
+	}
+	backingPath := copyPath
+	if entryPath != "" {
+		backingPath = filepath.Join(entryPath, "disk.qcow2")
+	} else if !isCopied {
+		return "", fmt.Errorf("neither the disk nor its image cache entry exists in %q", dir)
+	}
+
+	tmpPath := overlayPath + ".tmp"
+	if _, err := executeCommand("qemu-img", "create", "-f", "qcow2", "-F", "qcow2", "-b", backingPath, tmpPath); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, overlayPath); err != nil {
+		return "", err
+	}
+	if entryPath != "" && isCopied {
+		if err := os.Remove(copyPath); err != nil {
+			return "", err
+		}
+	}
+	return overlayPath, nil
+}
+
+// growEmptyHostDisk grows a host disk image to size if it's just created empty by kubelet, leaving existing images intact.
+func growEmptyHostDisk(path string, size int64) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > 0 {
+		return nil
+	}
+	return os.Truncate(path, size)
+}
+
+// groupThreadSiblings orders pCPUs so that sibling threads of a host core are adjacent, as are the
+// threads of a guest core, thus pinning guest threads of a core to threads of the same host core.
+func groupThreadSiblings(cpuSet cpuset.CPUSet) ([]int, error) {
+	var pcpus []int
+	grouped := map[int]bool{}
+	for _, pcpu := range cpuSet.ToSlice() {
+		if grouped[pcpu] {
+			continue
+		}
+
+		siblings, err := cpuset.GetThreadSiblings(pcpu)
+		if err != nil {
+			return nil, err
+		}
+		for _, sibling := range siblings.ToSlice() {
+			if _, ok := cpuSet[sibling]; ok && !grouped[sibling] {
+				pcpus = append(pcpus, sibling)
+				grouped[sibling] = true
+			}
+		}
+	}
+	return pcpus, nil
+}
+
+func buildNUMAConfig(vm *virtv1alpha1.VirtualMachine, pcpus []int, vmConfig *cloudhypervisor.VmConfig) error {
+	for i, node := range vm.Spec.Instance.NUMA.Nodes {
+		zoneConfig := cloudhypervisor.MemoryZoneConfig{
+			Id:           fmt.Sprintf("mem%d", i),
+			Size:         node.Memory.Value(),
+			Shared:       vmConfig.Memory.Shared,
+			Hugepages:    vmConfig.Memory.Hugepages,
+			HugepageSize: vmConfig.Memory.HugepageSize,
+			Mergeable:    vmConfig.Memory.Mergeable,
+			Prefault:     vmConfig.Memory.Prefault,
+		}
+
+		// bind the zone to the host NUMA node hosting most of its vCPUs
+		if len(pcpus) > 0 {
+			hostNodeCPUCounts := map[int]int{}
+			for _, vcpu := range node.CPUs {
+				hostNode, err := cpuset.GetNUMANode(pcpus[vcpu])
+				if err != nil {
+					return fmt.Errorf("get NUMA node of CPU %d: %s", pcpus[vcpu], err)
+				}
+				hostNodeCPUCounts[hostNode]++
+			}
+			for hostNode, count := range hostNodeCPUCounts {
+				if zoneConfig.HostNumaNode == nil || count > hostNodeCPUCounts[*zoneConfig.HostNumaNode] ||
+					(count == hostNodeCPUCounts[*zoneConfig.HostNumaNode] && hostNode < *zoneConfig.HostNumaNode) {
+					zoneConfig.HostNumaNode = &hostNode
+				}
+			}
+		}
+
+		vmConfig.Memory.Zones = append(vmConfig.Memory.Zones, &zoneConfig)
+		vmConfig.Numa = append(vmConfig.Numa, &cloudhypervisor.NumaConfig{
+			GuestNumaId: i,
+			Cpus:        node.CPUs,
+			MemoryZones: []string{zoneConfig.Id},
+		})
+	}
+
+	// memory is fully described by zones
+	vmConfig.Memory.Size = 0
+	return nil
+}
+
+// startSWTPM starts swtpm in the background and waits for its socket, since Cloud Hypervisor fails to start the VM if
+// the socket is not yet created.
+func startSWTPM(stateDir string, socketPath string) error {
+	cmd := exec.Command("swtpm", "socket", "--tpm2", "--tpmstate", "dir="+stateDir, "--ctrl", "type=unixio,path="+socketPath, "--flags", "startup-clear")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	return wait.PollImmediate(100*time.Millisecond, 10*time.Second, func() (bool, error) {
+		select {
+		case err := <-exited:
+			return false, fmt.Errorf("swtpm exited: %v", err)
+		default:
+		}
+		if _, err := os.Stat(socketPath); err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// buildNetRateLimiterConfig converts the rate limit of an interface, or the bandwidth annotations of the VM Pod for the
+// Pod network, into a rate limiter. Cloud Hypervisor applies the same limit to both directions, so the annotations are
+// only honored if the ingress and egress bandwidth are the same, which is enforced by the VM webhook.
+func buildNetRateLimiterConfig(vm *virtv1alpha1.VirtualMachine, iface *virtv1alpha1.Interface, network *virtv1alpha1.Network) *cloudhypervisor.RateLimiterConfig {
+	if iface.RateLimit == nil {
+		if network.Pod == nil {
+			return nil
+		}
+		ingressBandwidth, err := resource.ParseQuantity(vm.Annotations["kubernetes.io/ingress-bandwidth"])
+		if err != nil {
+			return nil
+		}
+		egressBandwidth, err := resource.ParseQuantity(vm.Annotations["kubernetes.io/egress-bandwidth"])
+		if err != nil || !egressBandwidth.Equal(ingressBandwidth) {
+			return nil
+		}
+		// bandwidth annotations are in bits per second
+		return cloudhypervisor.NewRateLimiterConfig(ingressBandwidth.Value()/8, 0, 0, 0)
+	}
+
+	var bandwidth, bandwidthBurst int64
+	if iface.RateLimit.Bandwidth != nil {
+		bandwidth = iface.RateLimit.Bandwidth.Value()
+	}
+	if iface.RateLimit.BandwidthBurst != nil {
+		bandwidthBurst = iface.RateLimit.BandwidthBurst.Value()
+	}
+	return cloudhypervisor.NewRateLimiterConfig(bandwidth, bandwidthBurst, iface.RateLimit.Packets, iface.RateLimit.PacketsBurst)
+}
+
 func setupBridgeNetwork(linkName string, cidr string, netConfig *cloudhypervisor.NetConfig) error {
 	_, subnet, err := net.ParseCIDR(cidr)
 	if err != nil {
@@ -374,7 +886,7 @@ func setupBridgeNetwork(linkName string, cidr string, netConfig *cloudhypervisor
 	}
 
 	tapName := fmt.Sprintf("tap-%s", linkName)
-	if _, err := createTap(bridge, tapName, link.Attrs().MTU); err != nil {
+	if _, err := createTap(bridge, tapName, link.Attrs().MTU, netConfig.NumQueues > 2); err != nil {
 		return fmt.Errorf("create tap: %s", err)
 	}
 	netConfig.Tap = tapName
@@ -442,7 +954,7 @@ func setupMasqueradeNetwork(linkName string, cidr string, netConfig *cloudhyperv
 	}
 
 	tapName := fmt.Sprintf("tap-%s", linkName)
-	if _, err := createTap(bridge, tapName, link.Attrs().MTU); err != nil {
+	if _, err := createTap(bridge, tapName, link.Attrs().MTU, netConfig.NumQueues > 2); err != nil {
 		return fmt.Errorf("create tap: %s", err)
 	}
 	netConfig.Tap = tapName
@@ -494,7 +1006,7 @@ func createBridge(bridgeName string, bridgeIPNet *net.IPNet, mtu int) (netlink.L
 	return bridge, nil
 }
 
-func createTap(bridge netlink.Link, tapName string, mtu int) (netlink.Link, error) {
+func createTap(bridge netlink.Link, tapName string, mtu int, multiQueue bool) (netlink.Link, error) {
 	tap := &netlink.Tuntap{
 		LinkAttrs: netlink.LinkAttrs{
 			Name: tapName,
@@ -503,6 +1015,9 @@ func createTap(bridge netlink.Link, tapName string, mtu int) (netlink.Link, erro
 		Mode:  netlink.TUNTAP_MODE_TAP,
 		Flags: netlink.TUNTAP_DEFAULTS,
 	}
+	if multiQueue {
+		tap.Flags = netlink.TUNTAP_MULTI_QUEUE_DEFAULTS | netlink.TUNTAP_VNET_HDR
+	}
 	if err := netlink.LinkAdd(tap); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cloudhypervisor"
+	"github.com/smartxworks/virtink/pkg/cpuset"
+)
+
+func TestUseCachedContainerDisk(t *testing.T) {
+	qcow2Data := append([]byte{'Q', 'F', 'I', 0xfb}, make([]byte, 1020)...)
+	rawData := make([]byte, 1024)
+
+	tests := []struct {
+		files           map[string][]byte
+		cached          bool
+		expectedDisk    string
+		expectedBacking string
+		expectedFiles   []string
+		expectedFail    bool
+	}{{
+		files:         map[string][]byte{"overlay.qcow2": qcow2Data, "disk.qcow2": qcow2Data},
+		expectedDisk:  "overlay.qcow2",
+		expectedFiles: []string{"overlay.qcow2", "disk.qcow2"},
+	}, {
+		files:           map[string][]byte{"disk.qcow2": qcow2Data, "image-cache-miss": nil},
+		expectedDisk:    "overlay.qcow2",
+		expectedBacking: "disk.qcow2",
+		expectedFiles:   []string{"overlay.qcow2", "disk.qcow2", "image-cache-miss"},
+	}, {
+		files:           map[string][]byte{"disk.qcow2": qcow2Data},
+		expectedDisk:    "overlay.qcow2",
+		expectedBacking: "disk.qcow2",
+		expectedFiles:   []string{"overlay.qcow2", "disk.qcow2"},
+	}, {
+		files:           map[string][]byte{},
+		cached:          true,
+		expectedDisk:    "overlay.qcow2",
+		expectedBacking: "entry/disk.qcow2",
+		expectedFiles:   []string{"overlay.qcow2", "image-cache-entry"},
+	}, {
+		// the copy is added to the cache before prerunner starts
+		files:           map[string][]byte{"disk.qcow2": qcow2Data},
+		cached:          true,
+		expectedDisk:    "overlay.qcow2",
+		expectedBacking: "entry/disk.qcow2",
+		expectedFiles:   []string{"overlay.qcow2", "image-cache-entry"},
+	}, {
+		// disks of images based on released versions of virtink-container-disk-base
+		files:         map[string][]byte{"disk.qcow2": rawData, "image-cache-miss": nil},
+		expectedDisk:  "disk.qcow2",
+		expectedFiles: []string{"disk.qcow2", "image-cache-miss"},
+	}, {
+		files:        map[string][]byte{"image-cache-miss": nil},
+		expectedFail: true,
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		volumeDir := filepath.Join(dir, "volume")
+		entryDir := filepath.Join(dir, "entry")
+		qemuImgLog := filepath.Join(dir, "qemu-img.log")
+		assert.NoError(t, os.Mkdir(binDir, 0755), "case %d", i)
+		assert.NoError(t, os.Mkdir(volumeDir, 0755), "case %d", i)
+		assert.NoError(t, os.WriteFile(filepath.Join(binDir, "qemu-img"), []byte(fakeQEMUImg), 0755), "case %d", i)
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		t.Setenv("QEMU_IMG_LOG", qemuImgLog)
+
+		for name, data := range tc.files {
+			assert.NoError(t, os.WriteFile(filepath.Join(volumeDir, name), data, 0644), "case %d", i)
+		}
+		if tc.cached {
+			assert.NoError(t, os.Mkdir(entryDir, 0755), "case %d", i)
+			assert.NoError(t, os.WriteFile(filepath.Join(entryDir, "disk.qcow2"), qcow2Data, 0644), "case %d", i)
+			assert.NoError(t, os.WriteFile(filepath.Join(volumeDir, "image-cache-entry"), []byte(entryDir), 0644), "case %d", i)
+		}
+
+		diskPath, err := useCachedContainerDisk(volumeDir)
+		if tc.expectedFail {
+			assert.Error(t, err, "case %d", i)
+			continue
+		}
+		if !assert.NoError(t, err, "case %d", i) {
+			continue
+		}
+		assert.Equal(t, filepath.Join(volumeDir, tc.expectedDisk), diskPath, "case %d", i)
+
+		qemuImgArgs, err := os.ReadFile(qemuImgLog)
+		if tc.expectedBacking == "" {
+			assert.True(t, os.IsNotExist(err), "case %d", i)
+		} else {
+			backingPath := filepath.Join(volumeDir, tc.expectedBacking)
+			if tc.cached {
+				backingPath = filepath.Join(dir, tc.expectedBacking)
+			}
+			assert.NoError(t, err, "case %d", i)
+			assert.Equal(t, "create -f qcow2 -F qcow2 -b "+backingPath+" "+filepath.Join(volumeDir, "overlay.qcow2.tmp")+"\n", string(qemuImgArgs), "case %d", i)
+		}
+
+		entries, err := os.ReadDir(volumeDir)
+		assert.NoError(t, err, "case %d", i)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, tc.expectedFiles, names, "case %d", i)
+	}
+}
+
+func TestUseCachedKernel(t *testing.T) {
+	tests := []struct {
+		files         []string
+		cached        bool
+		expectedFiles []string
+	}{{
+		files:         []string{"vmlinux", "initrd", "image-cache-miss"},
+		expectedFiles: []string{"vmlinux", "initrd", "image-cache-miss"},
+	}, {
+		cached:        true,
+		expectedFiles: []string{"image-cache-entry"},
+	}, {
+		// the copies are added to the cache before prerunner starts
+		files:         []string{"vmlinux", "initrd"},
+		cached:        true,
+		expectedFiles: []string{"image-cache-entry"},
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		volumeDir := filepath.Join(dir, "volume")
+		entryDir := filepath.Join(dir, "entry")
+		assert.NoError(t, os.Mkdir(volumeDir, 0755), "case %d", i)
+		for _, name := range tc.files {
+			assert.NoError(t, os.WriteFile(filepath.Join(volumeDir, name), nil, 0644), "case %d", i)
+		}
+		expectedDir := volumeDir
+		if tc.cached {
+			assert.NoError(t, os.WriteFile(filepath.Join(volumeDir, "image-cache-entry"), []byte(entryDir), 0644), "case %d", i)
+			expectedDir = entryDir
+		}
+
+		kernelDir, err := useCachedKernel(volumeDir)
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, expectedDir, kernelDir, "case %d", i)
+
+		entries, err := os.ReadDir(volumeDir)
+		assert.NoError(t, err, "case %d", i)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, tc.expectedFiles, names, "case %d", i)
+	}
+}
+
+func TestBuildNetRateLimiterConfig(t *testing.T) {
+	bandwidth := resource.MustParse("10Mi")
+	bandwidthBurst := resource.MustParse("1Mi")
+	podNetwork := virtv1alpha1.Network{
+		NetworkSource: virtv1alpha1.NetworkSource{
+			Pod: &virtv1alpha1.PodNetworkSource{},
+		},
+	}
+	multusNetwork := virtv1alpha1.Network{
+		NetworkSource: virtv1alpha1.NetworkSource{
+			Multus: &virtv1alpha1.MultusNetworkSource{},
+		},
+	}
+
+	tests := []struct {
+		annotations map[string]string
+		rateLimit   *virtv1alpha1.InterfaceRateLimit
+		network     virtv1alpha1.Network
+		expected    *cloudhypervisor.RateLimiterConfig
+	}{{
+		network: podNetwork,
+	}, {
+		rateLimit: &virtv1alpha1.InterfaceRateLimit{
+			Bandwidth:      &bandwidth,
+			BandwidthBurst: &bandwidthBurst,
+			Packets:        10000,
+			PacketsBurst:   1000,
+		},
+		network: multusNetwork,
+		expected: &cloudhypervisor.RateLimiterConfig{
+			Bandwidth: &cloudhypervisor.TokenBucket{Size: 10 * 1024 * 1024, OneTimeBurst: 1024 * 1024, RefillTime: 1000},
+			Ops:       &cloudhypervisor.TokenBucket{Size: 10000, OneTimeBurst: 1000, RefillTime: 1000},
+		},
+	}, {
+		rateLimit: &virtv1alpha1.InterfaceRateLimit{
+			Packets: 10000,
+		},
+		network: podNetwork,
+		expected: &cloudhypervisor.RateLimiterConfig{
+			Ops: &cloudhypervisor.TokenBucket{Size: 10000, RefillTime: 1000},
+		},
+	}, {
+		// annotations are in bits per second
+		annotations: map[string]string{
+			"kubernetes.io/ingress-bandwidth": "80M",
+			"kubernetes.io/egress-bandwidth":  "80M",
+		},
+		network: podNetwork,
+		expected: &cloudhypervisor.RateLimiterConfig{
+			Bandwidth: &cloudhypervisor.TokenBucket{Size: 10 * 1000 * 1000, RefillTime: 1000},
+		},
+	}, {
+		annotations: map[string]string{
+			"kubernetes.io/ingress-bandwidth": "80M",
+			"kubernetes.io/egress-bandwidth":  "80M",
+		},
+		network: multusNetwork,
+	}, {
+		annotations: map[string]string{
+			"kubernetes.io/ingress-bandwidth": "80M",
+			"kubernetes.io/egress-bandwidth":  "80M",
+		},
+		rateLimit: &virtv1alpha1.InterfaceRateLimit{
+			Bandwidth: &bandwidth,
+		},
+		network: podNetwork,
+		expected: &cloudhypervisor.RateLimiterConfig{
+			Bandwidth: &cloudhypervisor.TokenBucket{Size: 10 * 1024 * 1024, RefillTime: 1000},
+		},
+	}, {
+		annotations: map[string]string{
+			"kubernetes.io/ingress-bandwidth": "80M",
+		},
+		network: podNetwork,
+	}, {
+		annotations: map[string]string{
+			"kubernetes.io/ingress-bandwidth": "80M",
+			"kubernetes.io/egress-bandwidth":  "160M",
+		},
+		network: podNetwork,
+	}}
+
+	for i, tc := range tests {
+		vm := &virtv1alpha1.VirtualMachine{}
+		vm.Annotations = tc.annotations
+		iface := &virtv1alpha1.Interface{
+			RateLimit: tc.rateLimit,
+		}
+		assert.Equal(t, tc.expected, buildNetRateLimiterConfig(vm, iface, &tc.network), "case %d", i)
+	}
+}
+
+func TestStartSWTPM(t *testing.T) {
+	tests := []struct {
+		script       string
+		expectedFail bool
+	}{{
+		// the socket is created after a while
+		script: `#!/bin/sh
+for arg; do case $arg in type=unixio,path=*) socket=${arg#type=unixio,path=};; esac; done
+sleep 0.3
+touch "$socket"
+exec sleep 1
+`,
+	}, {
+		script: `#!/bin/sh
+exit 1
+`,
+		expectedFail: true,
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		assert.NoError(t, os.Mkdir(binDir, 0755), "case %d", i)
+		assert.NoError(t, os.WriteFile(filepath.Join(binDir, "swtpm"), []byte(tc.script), 0755), "case %d", i)
+		t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		socketPath := filepath.Join(dir, "swtpm.sock")
+		err := startSWTPM(filepath.Join(dir, "tpm"), socketPath)
+		if tc.expectedFail {
+			assert.Error(t, err, "case %d", i)
+			continue
+		}
+		assert.NoError(t, err, "case %d", i)
+		assert.FileExists(t, socketPath, "case %d", i)
+	}
+}
+
+func TestBuildVMConfig(t *testing.T) {
+	baseVM := &virtv1alpha1.VirtualMachine{
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Instance: virtv1alpha1.Instance{
+				CPU: virtv1alpha1.CPU{
+					Sockets:        1,
+					CoresPerSocket: 2,
+				},
+				Memory: virtv1alpha1.Memory{
+					Size: resource.MustParse("1Gi"),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		vm           *virtv1alpha1.VirtualMachine
+		cpuSet       cpuset.CPUSet
+		kernelFiles  []string
+		expectedFail bool
+		assert       func(vmConfig *cloudhypervisor.VmConfig)
+	}{{
+		vm: baseVM,
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, 2, vmConfig.Cpus.BootVcpus)
+			assert.Equal(t, 2, vmConfig.Cpus.Topology.CoresPerDie)
+			assert.Equal(t, int64(1<<30), vmConfig.Memory.Size)
+			assert.Empty(t, vmConfig.Cpus.Affinity)
+			assert.False(t, vmConfig.Memory.Prefault)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.CPU.DedicatedCPUPlacement = true
+			return vm
+		}(),
+		cpuSet: cpuset.NewCPUSet(4, 6),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, []*cloudhypervisor.CpuAffinity{{
+				Vcpu:     0,
+				HostCpus: []int{4},
+			}, {
+				Vcpu:     1,
+				HostCpus: []int{6},
+			}}, vmConfig.Cpus.Affinity)
+		},
+	}, {
+		// the last pCPU is left for the emulator thread
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.CPU.DedicatedCPUPlacement = true
+			vm.Spec.Instance.CPU.IsolateEmulatorThread = true
+			return vm
+		}(),
+		cpuSet: cpuset.NewCPUSet(4, 6, 7),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, []*cloudhypervisor.CpuAffinity{{
+				Vcpu:     0,
+				HostCpus: []int{4},
+			}, {
+				Vcpu:     1,
+				HostCpus: []int{6},
+			}}, vmConfig.Cpus.Affinity)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.CPU.DedicatedCPUPlacement = true
+			vm.Spec.Instance.CPU.IsolateEmulatorThread = true
+			return vm
+		}(),
+		cpuSet:       cpuset.NewCPUSet(4, 6),
+		expectedFail: true,
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.Memory.Prefault = true
+			return vm
+		}(),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.True(t, vmConfig.Memory.Prefault)
+		},
+	}, {
+		// guest memory of realtime VMs is prefaulted, so it's locked in RAM with hugepages
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.CPU.Realtime = &virtv1alpha1.CPURealtime{
+				Priority: 10,
+			}
+			vm.Spec.Instance.Memory.Hugepages = &virtv1alpha1.Hugepages{
+				PageSize: "1Gi",
+			}
+			return vm
+		}(),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.True(t, vmConfig.Memory.Prefault)
+			assert.True(t, vmConfig.Memory.Hugepages)
+			assert.Equal(t, int64(1<<30), vmConfig.Memory.HugepageSize)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.Kernel = &virtv1alpha1.Kernel{
+				Image:   "kernel",
+				Cmdline: "console=ttyS0 root=/dev/vda rw",
+			}
+			return vm
+		}(),
+		kernelFiles: []string{"vmlinux", "initrd"},
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, filepath.Join(kernelVolumePath, "vmlinux"), vmConfig.Payload.Kernel)
+			assert.Equal(t, filepath.Join(kernelVolumePath, "initrd"), vmConfig.Payload.Initramfs)
+			assert.Equal(t, "console=ttyS0 root=/dev/vda rw", vmConfig.Payload.Cmdline)
+		},
+	}, {
+		// kernel images may not provide an initrd
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.Kernel = &virtv1alpha1.Kernel{
+				Image:   "kernel",
+				Cmdline: "console=ttyS0 root=/dev/vda rw",
+			}
+			return vm
+		}(),
+		kernelFiles: []string{"vmlinux"},
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, filepath.Join(kernelVolumePath, "vmlinux"), vmConfig.Payload.Kernel)
+			assert.Empty(t, vmConfig.Payload.Initramfs)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Image: "firmware",
+			}
+			return vm
+		}(),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, "/mnt/virtink-firmware/firmware", vmConfig.Payload.Kernel)
+			assert.Empty(t, vmConfig.Payload.Initramfs)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.Spec.Instance.Watchdog = &virtv1alpha1.Watchdog{}
+			vm.Spec.Instance.Pvpanic = &virtv1alpha1.Pvpanic{}
+			vm.Spec.Instance.RNG = &virtv1alpha1.RNG{}
+			return vm
+		}(),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.True(t, vmConfig.Watchdog)
+			assert.True(t, vmConfig.Pvpanic)
+			assert.Equal(t, &cloudhypervisor.RngConfig{Src: "/dev/urandom"}, vmConfig.Rng)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := baseVM.DeepCopy()
+			vm.UID = "c1f2a7b6-5d8e-4c3a-9b0f-1e2d3c4b5a69"
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{
+				SerialNumber: "vm-0001",
+			}
+			return vm
+		}(),
+		assert: func(vmConfig *cloudhypervisor.VmConfig) {
+			assert.Equal(t, "c1f2a7b6-5d8e-4c3a-9b0f-1e2d3c4b5a69", vmConfig.Platform.Uuid)
+			assert.Equal(t, "vm-0001", vmConfig.Platform.SerialNumber)
+		},
+	}}
+
+	for i, tc := range tests {
+		getCPUSet = func() (cpuset.CPUSet, error) {
+			return tc.cpuSet, nil
+		}
+		t.Cleanup(func() {
+			getCPUSet = cpuset.Get
+		})
+		kernelVolumePath = t.TempDir()
+		t.Cleanup(func() {
+			kernelVolumePath = "/mnt/virtink-kernel"
+		})
+		for _, name := range tc.kernelFiles {
+			assert.NoError(t, os.WriteFile(filepath.Join(kernelVolumePath, name), nil, 0644), "case %d", i)
+		}
+
+		vmConfig, err := buildVMConfig(context.Background(), tc.vm)
+		if tc.expectedFail {
+			assert.Error(t, err, "case %d", i)
+			continue
+		}
+		if assert.NoError(t, err, "case %d", i) {
+			tc.assert(vmConfig)
+		}
+	}
+}
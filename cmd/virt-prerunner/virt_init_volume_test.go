@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const virtInitVolumeScript = "../../build/virt-prerunner/virt-init-volume.sh"
+
+// fakeQEMUImg records its arguments and copies its source to its target, so that the import script can be tested
+// without qemu-img.
+const fakeQEMUImg = `#!/bin/sh
+echo "$@" > "$QEMU_IMG_LOG"
+for arg; do source=$target; target=$arg; done
+cp "$source" "$target"
+`
+
+func TestVirtInitVolumeImport(t *testing.T) {
+	// The script runs with busybox sh in the prerunner image.
+	shell := []string{"busybox", "sh"}
+	if _, err := exec.LookPath("busybox"); err != nil {
+		shell = []string{"bash", "--posix"}
+	}
+
+	data := []byte("disk")
+	digest := sha256.Sum256(data)
+	checksum := "sha256:" + hex.EncodeToString(digest[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/disk.img" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		source       string
+		checksum     string
+		expectedFail bool
+	}{{
+		source:   "file",
+		checksum: checksum,
+	}, {
+		source: "file",
+	}, {
+		source:       "file",
+		checksum:     "sha256:" + strings.Repeat("0", 64),
+		expectedFail: true,
+	}, {
+		source:   server.URL + "/disk.img",
+		checksum: checksum,
+	}, {
+		source:       server.URL + "/missing.img",
+		expectedFail: true,
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		scratchDir := filepath.Join(dir, "scratch")
+		assert.NoError(t, os.Mkdir(binDir, 0755), "case %d", i)
+		assert.NoError(t, os.Mkdir(scratchDir, 0755), "case %d", i)
+		assert.NoError(t, os.WriteFile(filepath.Join(binDir, "qemu-img"), []byte(fakeQEMUImg), 0755), "case %d", i)
+
+		source := tc.source
+		expectedSource := filepath.Join(scratchDir, "disk")
+		if source == "file" {
+			source = filepath.Join(dir, "disk")
+			expectedSource = source
+			assert.NoError(t, os.WriteFile(source, data, 0644), "case %d", i)
+		}
+		target := filepath.Join(dir, "disk.img")
+		qemuImgLog := filepath.Join(dir, "qemu-img.log")
+
+		cmd := exec.Command(shell[0], append(shell[1:], virtInitVolumeScript, "import", source, tc.checksum, target)...)
+		cmd.Env = append(os.Environ(),
+			"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+			"IMPORT_SCRATCH_DIR="+scratchDir,
+			"QEMU_IMG_LOG="+qemuImgLog,
+		)
+		output, err := cmd.CombinedOutput()
+		if tc.expectedFail {
+			assert.Error(t, err, "case %d", i)
+			assert.NoFileExists(t, target, "case %d", i)
+			continue
+		}
+		if !assert.NoError(t, err, "case %d: %s", i, output) {
+			continue
+		}
+
+		qemuImgArgs, err := os.ReadFile(qemuImgLog)
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, "convert -O raw "+expectedSource+" "+target+"\n", string(qemuImgArgs), "case %d", i)
+		actualData, err := os.ReadFile(target)
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, data, actualData, "case %d", i)
+	}
+}
@@ -35,6 +35,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VirtualMachineList{},
 		&VirtualMachineMigration{},
 		&VirtualMachineMigrationList{},
+		&VirtualMachineVolumeMove{},
+		&VirtualMachineVolumeMoveList{},
+		&VolumeImport{},
+		&VolumeImportList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
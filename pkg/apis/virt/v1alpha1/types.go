@@ -52,52 +52,236 @@ const (
 )
 
 type Instance struct {
-	CPU         CPU          `json:"cpu,omitempty"`
-	Memory      Memory       `json:"memory,omitempty"`
-	Kernel      *Kernel      `json:"kernel,omitempty"`
-	Disks       []Disk       `json:"disks,omitempty"`
-	FileSystems []FileSystem `json:"fileSystems,omitempty"`
-	Interfaces  []Interface  `json:"interfaces,omitempty"`
+	CPU               CPU                `json:"cpu,omitempty"`
+	Memory            Memory             `json:"memory,omitempty"`
+	Kernel            *Kernel            `json:"kernel,omitempty"`
+	Firmware          *Firmware          `json:"firmware,omitempty"`
+	Disks             []Disk             `json:"disks,omitempty"`
+	DiskIOLimitGroups []DiskIOLimitGroup `json:"diskIOLimitGroups,omitempty"`
+	FileSystems       []FileSystem       `json:"fileSystems,omitempty"`
+	PmemDevices       []PmemDevice       `json:"pmemDevices,omitempty"`
+	Interfaces        []Interface        `json:"interfaces,omitempty"`
+	NUMA              *NUMA              `json:"numa,omitempty"`
+	TPM               *TPM               `json:"tpm,omitempty"`
+	Watchdog          *Watchdog          `json:"watchdog,omitempty"`
+	Pvpanic           *Pvpanic           `json:"pvpanic,omitempty"`
+	RNG               *RNG               `json:"rng,omitempty"`
+	Platform          *Platform          `json:"platform,omitempty"`
 }
 
 type CPU struct {
-	Sockets               uint32 `json:"sockets,omitempty"`
-	CoresPerSocket        uint32 `json:"coresPerSocket,omitempty"`
-	DedicatedCPUPlacement bool   `json:"dedicatedCPUPlacement,omitempty"`
-}
+	Sockets        uint32 `json:"sockets,omitempty"`
+	CoresPerSocket uint32 `json:"coresPerSocket,omitempty"`
+	// ThreadsPerCore defaults to 1 on creation. VMs created before it was added may have it unset, which is treated
+	// as 1.
+	ThreadsPerCore        uint32       `json:"threadsPerCore,omitempty"`
+	DedicatedCPUPlacement bool         `json:"dedicatedCPUPlacement,omitempty"`
+	IsolateEmulatorThread bool         `json:"isolateEmulatorThread,omitempty"`
+	Realtime              *CPURealtime `json:"realtime,omitempty"`
+	// MaxPhysBits is the maximum number of physical address bits of the guest, up to 52. If unset or 0, the number
+	// of physical address bits of the host is used.
+	MaxPhysBits int          `json:"maxPhysBits,omitempty"`
+	KVMHyperv   bool         `json:"kvmHyperv,omitempty"`
+	Features    []CPUFeature `json:"features,omitempty"`
+}
+
+type CPURealtime struct {
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=amx
+type CPUFeature string
+
+const (
+	CPUFeatureAMX CPUFeature = "amx"
+)
 
 type Memory struct {
-	Size      resource.Quantity `json:"size,omitempty"`
-	Hugepages *Hugepages        `json:"hugepages,omitempty"`
+	Size                 resource.Quantity `json:"size,omitempty"`
+	Hugepages            *Hugepages        `json:"hugepages,omitempty"`
+	TransparentHugepages *bool             `json:"transparentHugepages,omitempty"`
+	Mergeable            bool              `json:"mergeable,omitempty"`
+	Prefault             bool              `json:"prefault,omitempty"`
 }
 
 type Hugepages struct {
 	// +kubebuilder:default="1Gi"
-	// +kubebuilder:validation:Enum="2Mi";"1Gi"
 	PageSize string `json:"pageSize,omitempty"`
 }
 
+type NUMA struct {
+	Nodes []NUMANode `json:"nodes"`
+}
+
+type NUMANode struct {
+	CPUs   []int             `json:"cpus"`
+	Memory resource.Quantity `json:"memory"`
+}
+
 type Kernel struct {
 	Image           string            `json:"image"`
 	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 	Cmdline         string            `json:"cmdline"`
 }
 
+type Firmware struct {
+	Type            FirmwareType      `json:"type,omitempty"`
+	Image           string            `json:"image,omitempty"`
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=RustHypervisorFirmware;OVMF
+type FirmwareType string
+
+const (
+	FirmwareRustHypervisorFirmware FirmwareType = "RustHypervisorFirmware"
+	FirmwareOVMF                   FirmwareType = "OVMF"
+)
+
+type TPM struct {
+	// ClaimName is the name of a filesystem PVC to persist TPM state. TPM state is lost when the VM stops if not specified
+	ClaimName string `json:"claimName,omitempty"`
+}
+
+type Watchdog struct {
+	// Action is the action to take when the watchdog expires
+	// +kubebuilder:default=Reset
+	Action WatchdogAction `json:"action,omitempty"`
+}
+
+// Cloud Hypervisor always resets the VM when the watchdog expires, so the action is taken after the reset, and None
+// only reports the expiry like Reset.
+// +kubebuilder:validation:Enum=Reset;PowerOff;None
+type WatchdogAction string
+
+const (
+	WatchdogActionReset    WatchdogAction = "Reset"
+	WatchdogActionPowerOff WatchdogAction = "PowerOff"
+	WatchdogActionNone     WatchdogAction = "None"
+)
+
+type Pvpanic struct {
+	// Action is the action to take when the guest panics
+	// +kubebuilder:default=Reset
+	Action GuestPanicAction `json:"action,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Reset;PowerOff;None
+type GuestPanicAction string
+
+const (
+	GuestPanicActionReset    GuestPanicAction = "Reset"
+	GuestPanicActionPowerOff GuestPanicAction = "PowerOff"
+	GuestPanicActionNone     GuestPanicAction = "None"
+)
+
+// RNG configures the virtio-rng device. It has no rate limit, since Cloud Hypervisor doesn't support rate limiting
+// virtio-rng devices
+type RNG struct {
+	// Source is the entropy source on the host
+	// +kubebuilder:default=/dev/urandom
+	// +kubebuilder:validation:Enum=/dev/urandom;/dev/random
+	Source string `json:"source,omitempty"`
+}
+
+type Platform struct {
+	// UUID is the SMBIOS system UUID. Defaults to the UID of the VM
+	UUID         string   `json:"uuid,omitempty"`
+	SerialNumber string   `json:"serialNumber,omitempty"`
+	OEMStrings   []string `json:"oemStrings,omitempty"`
+}
+
 type Disk struct {
-	Name     string `json:"name"`
-	ReadOnly *bool  `json:"readOnly,omitempty"`
+	Name         string        `json:"name"`
+	ReadOnly     *bool         `json:"readOnly,omitempty"`
+	IOLimits     *DiskIOLimits `json:"ioLimits,omitempty"`
+	IOLimitGroup string        `json:"ioLimitGroup,omitempty"`
+	NumQueues    int           `json:"numQueues,omitempty"`
+	QueueSize    int           `json:"queueSize,omitempty"`
+	// Cache is the host page cache mode of the disk. Defaults to none
+	Cache DiskCache `json:"cache,omitempty"`
+	// Serial is the serial number of the disk seen by the guest. Defaults to the disk name truncated to 20 bytes
+	Serial string `json:"serial,omitempty"`
+}
+
+// GetThreadsPerCore returns the number of threads per core, which is 1 if unset.
+func (c *CPU) GetThreadsPerCore() uint32 {
+	if c.ThreadsPerCore == 0 {
+		return 1
+	}
+	return c.ThreadsPerCore
+}
+
+// MaxDiskSerialLength is the length limit of virtio-blk serial numbers
+const MaxDiskSerialLength = 20
+
+// GetSerial returns the serial number of the disk, which defaults to the disk name truncated to MaxDiskSerialLength.
+func (d *Disk) GetSerial() string {
+	if d.Serial != "" {
+		return d.Serial
+	}
+	if len(d.Name) > MaxDiskSerialLength {
+		return d.Name[:MaxDiskSerialLength]
+	}
+	return d.Name
+}
+
+// +kubebuilder:validation:Enum=none;writeback
+type DiskCache string
+
+const (
+	DiskCacheNone      DiskCache = "none"
+	DiskCacheWriteback DiskCache = "writeback"
+)
+
+type DiskIOLimits struct {
+	// Bandwidth is the maximum bytes per second
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+	// BandwidthBurst is the bytes allowed in a one-time burst on top of Bandwidth
+	BandwidthBurst *resource.Quantity `json:"bandwidthBurst,omitempty"`
+	IOPS           int64              `json:"iops,omitempty"`
+	IOPSBurst      int64              `json:"iopsBurst,omitempty"`
+}
+
+type DiskIOLimitGroup struct {
+	Name         string `json:"name"`
+	DiskIOLimits `json:",inline"`
 }
 
 type FileSystem struct {
 	Name string `json:"name"`
 }
 
+type PmemDevice struct {
+	Name string `json:"name"`
+	// DiscardWrites discards guest writes to the device instead of writing them back to the volume
+	DiscardWrites bool `json:"discardWrites,omitempty"`
+}
+
 type Interface struct {
-	Name                   string `json:"name"`
-	MAC                    string `json:"mac,omitempty"`
+	Name                   string              `json:"name"`
+	MAC                    string              `json:"mac,omitempty"`
+	RateLimit              *InterfaceRateLimit `json:"rateLimit,omitempty"`
+	NumQueues              int                 `json:"numQueues,omitempty"`
+	QueueSize              int                 `json:"queueSize,omitempty"`
 	InterfaceBindingMethod `json:",inline"`
 }
 
+// InterfaceRateLimit limits the received and the transmitted traffic of an interface separately, each to the same
+// rates, since Cloud Hypervisor takes a single rate limiter for both directions
+type InterfaceRateLimit struct {
+	// Bandwidth is the maximum bytes per second in each direction, in the same unit as the bandwidth of disk IO limits
+	Bandwidth *resource.Quantity `json:"bandwidth,omitempty"`
+	// BandwidthBurst is the bytes allowed in a one-time burst on top of Bandwidth
+	BandwidthBurst *resource.Quantity `json:"bandwidthBurst,omitempty"`
+	// Packets is the maximum packets per second in each direction
+	Packets      int64 `json:"packets,omitempty"`
+	PacketsBurst int64 `json:"packetsBurst,omitempty"`
+}
+
 type InterfaceBindingMethod struct {
 	Bridge     *InterfaceBridge     `json:"bridge,omitempty"`
 	Masquerade *InterfaceMasquerade `json:"masquerade,omitempty"`
@@ -151,6 +335,21 @@ type VolumeSource struct {
 	ContainerRootfs       *ContainerRootfsVolumeSource       `json:"containerRootfs,omitempty"`
 	PersistentVolumeClaim *PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
 	DataVolume            *DataVolumeVolumeSource            `json:"dataVolume,omitempty"`
+	VhostUser             *VhostUserVolumeSource             `json:"vhostUser,omitempty"`
+	EmptyDisk             *EmptyDiskVolumeSource             `json:"emptyDisk,omitempty"`
+	HostDisk              *HostDiskVolumeSource              `json:"hostDisk,omitempty"`
+	// ConfigMap exposes the data of a ConfigMap to the guest. Attached as a disk, the files are packed into an ISO
+	// image once when the VM starts, so changes to the ConfigMap are not visible in the guest until the VM restarts.
+	// Attached as a file system, changes are visible after kubelet syncs the volume
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// Secret exposes the data of a Secret to the guest. Like ConfigMap, changes to the Secret, such as rotated
+	// credentials, are not visible in the guest until the VM restarts if it's attached as a disk
+	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+	// ServiceAccount exposes the token of a ServiceAccount to the guest, and may only be attached as a file system
+	ServiceAccount *ServiceAccountVolumeSource `json:"serviceAccount,omitempty"`
+	// DownwardAPI exposes fields of the VM Pod to the guest. Like ConfigMap, changes are not visible in the guest until
+	// the VM restarts if it's attached as a disk
+	DownwardAPI *corev1.DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
 }
 
 type ContainerDiskVolumeSource struct {
@@ -183,6 +382,44 @@ type DataVolumeVolumeSource struct {
 	VolumeName   string `json:"volumeName"`
 }
 
+type VhostUserVolumeSource struct {
+	// HostPath is the path of the vhost-user-blk socket on the host
+	HostPath string `json:"hostPath,omitempty"`
+	// ClaimName is the name of a filesystem PVC, in which the vhost-user-blk socket is provided by the CSI driver
+	ClaimName string `json:"claimName,omitempty"`
+	// SocketName is the file name of the vhost-user-blk socket in the PVC
+	SocketName string `json:"socketName,omitempty"`
+}
+
+type EmptyDiskVolumeSource struct {
+	Capacity resource.Quantity `json:"capacity"`
+}
+
+type HostDiskVolumeSource struct {
+	// Path is the path of the disk image file on the node
+	Path string `json:"path"`
+	// +kubebuilder:default=Disk
+	Type HostDiskType `json:"type,omitempty"`
+	// Capacity is the size of the disk image created when it does not exist. Required for DiskOrCreate
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// NodeName is the node where the disk image is located, to which the VM is pinned
+	NodeName string `json:"nodeName"`
+}
+
+// +kubebuilder:validation:Enum=Disk;DiskOrCreate
+type HostDiskType string
+
+const (
+	HostDiskExists         HostDiskType = "Disk"
+	HostDiskExistsOrCreate HostDiskType = "DiskOrCreate"
+)
+
+type ServiceAccountVolumeSource struct {
+	// ServiceAccountName is the name of the ServiceAccount, with which the VM Pod runs instead of the default
+	// ServiceAccount of the namespace, so the VM Pod has the permissions granted to the ServiceAccount
+	ServiceAccountName string `json:"serviceAccountName"`
+}
+
 type Network struct {
 	Name          string `json:"name"`
 	NetworkSource `json:",inline"`
@@ -202,14 +439,15 @@ type MultusNetworkSource struct {
 
 // VirtualMachineStatus is the status for a VirtualMachine resource
 type VirtualMachineStatus struct {
-	Phase        VirtualMachinePhase            `json:"phase,omitempty"`
-	VMPodName    string                         `json:"vmPodName,omitempty"`
-	VMPodUID     types.UID                      `json:"vmPodUID,omitempty"`
-	NodeName     string                         `json:"nodeName,omitempty"`
-	PowerAction  VirtualMachinePowerAction      `json:"powerAction,omitempty"`
-	Migration    *VirtualMachineStatusMigration `json:"migration,omitempty"`
-	Conditions   []metav1.Condition             `json:"conditions,omitempty"`
-	VolumeStatus []VolumeStatus                 `json:"volumeStatus,omitempty"`
+	Phase        VirtualMachinePhase             `json:"phase,omitempty"`
+	VMPodName    string                          `json:"vmPodName,omitempty"`
+	VMPodUID     types.UID                       `json:"vmPodUID,omitempty"`
+	NodeName     string                          `json:"nodeName,omitempty"`
+	PowerAction  VirtualMachinePowerAction       `json:"powerAction,omitempty"`
+	Migration    *VirtualMachineStatusMigration  `json:"migration,omitempty"`
+	Conditions   []metav1.Condition              `json:"conditions,omitempty"`
+	VolumeStatus []VolumeStatus                  `json:"volumeStatus,omitempty"`
+	VolumeMove   *VirtualMachineStatusVolumeMove `json:"volumeMove,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Pending;Scheduling;Scheduled;Running;Succeeded;Failed;Unknown
@@ -251,17 +489,27 @@ type VirtualMachineStatusMigration struct {
 	TargetVolumePodUID types.UID                    `json:"targetVolumePodUID,omitempty"`
 }
 
+type VirtualMachineStatusVolumeMove struct {
+	UID          types.UID                     `json:"uid,omitempty"`
+	Phase        VirtualMachineVolumeMovePhase `json:"phase,omitempty"`
+	VolumeName   string                        `json:"volumeName,omitempty"`
+	TargetVolume Volume                        `json:"targetVolume,omitempty"`
+}
+
 type VirtualMachineConditionType string
 
 const (
-	VirtualMachineMigratable VirtualMachineConditionType = "Migratable"
-	VirtualMachineReady      VirtualMachineConditionType = "Ready"
+	VirtualMachineMigratable    VirtualMachineConditionType = "Migratable"
+	VirtualMachineReady         VirtualMachineConditionType = "Ready"
+	VirtualMachineGuestPanicked VirtualMachineConditionType = "GuestPanicked"
 )
 
 type VolumeStatus struct {
-	Name          string               `json:"name"`
-	Phase         VolumePhase          `json:"phase,omitempty"`
-	HotplugVolume *HotplugVolumeStatus `json:"hotplugVolume,omitempty"`
+	Name          string                     `json:"name"`
+	Phase         VolumePhase                `json:"phase,omitempty"`
+	HotplugVolume *HotplugVolumeStatus       `json:"hotplugVolume,omitempty"`
+	ContainerDisk *ContainerDiskVolumeStatus `json:"containerDisk,omitempty"`
+	Capacity      *resource.Quantity         `json:"capacity,omitempty"`
 }
 
 type VolumePhase string
@@ -279,6 +527,11 @@ type HotplugVolumeStatus struct {
 	VolumePodUID  types.UID `json:"volumePodUID,omitempty"`
 }
 
+type ContainerDiskVolumeStatus struct {
+	// Format is the format of the disk image in the container image, such as raw or qcow2.
+	Format string `json:"format,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // VirtualMachineList is a list of VirtualMachine resources
@@ -339,3 +592,122 @@ type VirtualMachineMigrationList struct {
 
 	Items []VirtualMachineMigration `json:"items"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=vmvm
+// +kubebuilder:printcolumn:name="VM",type=string,JSONPath=`.spec.vmName`
+// +kubebuilder:printcolumn:name="Volume",type=string,JSONPath=`.spec.volumeName`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+
+// VirtualMachineVolumeMove moves the data of a hotpluggable disk of a running VM to another PVC by copying the disk
+// and replugging it. It's not a live migration: the disk is offline in the guest while it's replugged.
+type VirtualMachineVolumeMove struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VirtualMachineVolumeMoveSpec   `json:"spec,omitempty"`
+	Status VirtualMachineVolumeMoveStatus `json:"status,omitempty"`
+}
+
+type VirtualMachineVolumeMoveSpec struct {
+	VMName       string       `json:"vmName"`
+	VolumeName   string       `json:"volumeName"`
+	TargetVolume VolumeSource `json:"targetVolume"`
+}
+
+type VirtualMachineVolumeMoveStatus struct {
+	Phase VirtualMachineVolumeMovePhase `json:"phase,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Pending;Attaching;Copying;Switching;Switched;Succeeded;Failed
+
+type VirtualMachineVolumeMovePhase string
+
+const (
+	VirtualMachineVolumeMovePending   VirtualMachineVolumeMovePhase = "Pending"
+	VirtualMachineVolumeMoveAttaching VirtualMachineVolumeMovePhase = "Attaching"
+	VirtualMachineVolumeMoveCopying   VirtualMachineVolumeMovePhase = "Copying"
+	VirtualMachineVolumeMoveSwitching VirtualMachineVolumeMovePhase = "Switching"
+	VirtualMachineVolumeMoveSwitched  VirtualMachineVolumeMovePhase = "Switched"
+	VirtualMachineVolumeMoveSucceeded VirtualMachineVolumeMovePhase = "Succeeded"
+	VirtualMachineVolumeMoveFailed    VirtualMachineVolumeMovePhase = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VirtualMachineVolumeMoveList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VirtualMachineVolumeMove `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=vi
+// +kubebuilder:printcolumn:name="Claim",type=string,JSONPath=`.spec.claimName`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.phase`
+
+// VolumeImport populates a PVC with a disk image, which can then be used by VMs as a
+// persistentVolumeClaim volume.
+type VolumeImport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeImportSpec   `json:"spec,omitempty"`
+	Status VolumeImportStatus `json:"status,omitempty"`
+}
+
+type VolumeImportSpec struct {
+	Source VolumeImportSource `json:"source"`
+	// ClaimName is the name of the PVC to import the disk image into. The disk image is converted to a
+	// raw disk.img in a Filesystem mode PVC, or written to the device of a Block mode PVC.
+	ClaimName string `json:"claimName"`
+}
+
+type VolumeImportSource struct {
+	HTTP     *VolumeImportHTTPSource     `json:"http,omitempty"`
+	Registry *VolumeImportRegistrySource `json:"registry,omitempty"`
+}
+
+type VolumeImportHTTPSource struct {
+	URL string `json:"url"`
+	// Checksum is the checksum of the downloaded file, in the form of sha256:<hex> or sha512:<hex>.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+type VolumeImportRegistrySource struct {
+	// Image is a containerDisk image with the disk image placed at /disk.
+	Image           string            `json:"image"`
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Checksum is the checksum of the disk image, in the form of sha256:<hex> or sha512:<hex>.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+type VolumeImportStatus struct {
+	Phase           VolumeImportPhase `json:"phase,omitempty"`
+	ImporterPodName string            `json:"importerPodName,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+
+type VolumeImportPhase string
+
+const (
+	VolumeImportPending   VolumeImportPhase = "Pending"
+	VolumeImportRunning   VolumeImportPhase = "Running"
+	VolumeImportSucceeded VolumeImportPhase = "Succeeded"
+	VolumeImportFailed    VolumeImportPhase = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VolumeImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeImport `json:"items"`
+}
@@ -14,6 +14,16 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CPU) DeepCopyInto(out *CPU) {
 	*out = *in
+	if in.Realtime != nil {
+		in, out := &in.Realtime, &out.Realtime
+		*out = new(CPURealtime)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]CPUFeature, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -27,6 +37,22 @@ func (in *CPU) DeepCopy() *CPU {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPURealtime) DeepCopyInto(out *CPURealtime) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPURealtime.
+func (in *CPURealtime) DeepCopy() *CPURealtime {
+	if in == nil {
+		return nil
+	}
+	out := new(CPURealtime)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudInitVolumeSource) DeepCopyInto(out *CloudInitVolumeSource) {
 	*out = *in
@@ -59,6 +85,22 @@ func (in *ContainerDiskVolumeSource) DeepCopy() *ContainerDiskVolumeSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerDiskVolumeStatus) DeepCopyInto(out *ContainerDiskVolumeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerDiskVolumeStatus.
+func (in *ContainerDiskVolumeStatus) DeepCopy() *ContainerDiskVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerDiskVolumeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerRootfsVolumeSource) DeepCopyInto(out *ContainerRootfsVolumeSource) {
 	*out = *in
@@ -100,6 +142,11 @@ func (in *Disk) DeepCopyInto(out *Disk) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IOLimits != nil {
+		in, out := &in.IOLimits, &out.IOLimits
+		*out = new(DiskIOLimits)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -113,6 +160,66 @@ func (in *Disk) DeepCopy() *Disk {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskIOLimitGroup) DeepCopyInto(out *DiskIOLimitGroup) {
+	*out = *in
+	in.DiskIOLimits.DeepCopyInto(&out.DiskIOLimits)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskIOLimitGroup.
+func (in *DiskIOLimitGroup) DeepCopy() *DiskIOLimitGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskIOLimitGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskIOLimits) DeepCopyInto(out *DiskIOLimits) {
+	*out = *in
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BandwidthBurst != nil {
+		in, out := &in.BandwidthBurst, &out.BandwidthBurst
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskIOLimits.
+func (in *DiskIOLimits) DeepCopy() *DiskIOLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskIOLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EmptyDiskVolumeSource) DeepCopyInto(out *EmptyDiskVolumeSource) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EmptyDiskVolumeSource.
+func (in *EmptyDiskVolumeSource) DeepCopy() *EmptyDiskVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EmptyDiskVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FileSystem) DeepCopyInto(out *FileSystem) {
 	*out = *in
@@ -129,6 +236,43 @@ func (in *FileSystem) DeepCopy() *FileSystem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Firmware) DeepCopyInto(out *Firmware) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Firmware.
+func (in *Firmware) DeepCopy() *Firmware {
+	if in == nil {
+		return nil
+	}
+	out := new(Firmware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostDiskVolumeSource) DeepCopyInto(out *HostDiskVolumeSource) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDiskVolumeSource.
+func (in *HostDiskVolumeSource) DeepCopy() *HostDiskVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HostDiskVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HotplugVolumeStatus) DeepCopyInto(out *HotplugVolumeStatus) {
 	*out = *in
@@ -164,13 +308,18 @@ func (in *Hugepages) DeepCopy() *Hugepages {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Instance) DeepCopyInto(out *Instance) {
 	*out = *in
-	out.CPU = in.CPU
+	in.CPU.DeepCopyInto(&out.CPU)
 	in.Memory.DeepCopyInto(&out.Memory)
 	if in.Kernel != nil {
 		in, out := &in.Kernel, &out.Kernel
 		*out = new(Kernel)
 		**out = **in
 	}
+	if in.Firmware != nil {
+		in, out := &in.Firmware, &out.Firmware
+		*out = new(Firmware)
+		**out = **in
+	}
 	if in.Disks != nil {
 		in, out := &in.Disks, &out.Disks
 		*out = make([]Disk, len(*in))
@@ -178,11 +327,23 @@ func (in *Instance) DeepCopyInto(out *Instance) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DiskIOLimitGroups != nil {
+		in, out := &in.DiskIOLimitGroups, &out.DiskIOLimitGroups
+		*out = make([]DiskIOLimitGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.FileSystems != nil {
 		in, out := &in.FileSystems, &out.FileSystems
 		*out = make([]FileSystem, len(*in))
 		copy(*out, *in)
 	}
+	if in.PmemDevices != nil {
+		in, out := &in.PmemDevices, &out.PmemDevices
+		*out = make([]PmemDevice, len(*in))
+		copy(*out, *in)
+	}
 	if in.Interfaces != nil {
 		in, out := &in.Interfaces, &out.Interfaces
 		*out = make([]Interface, len(*in))
@@ -190,6 +351,36 @@ func (in *Instance) DeepCopyInto(out *Instance) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NUMA != nil {
+		in, out := &in.NUMA, &out.NUMA
+		*out = new(NUMA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TPM != nil {
+		in, out := &in.TPM, &out.TPM
+		*out = new(TPM)
+		**out = **in
+	}
+	if in.Watchdog != nil {
+		in, out := &in.Watchdog, &out.Watchdog
+		*out = new(Watchdog)
+		**out = **in
+	}
+	if in.Pvpanic != nil {
+		in, out := &in.Pvpanic, &out.Pvpanic
+		*out = new(Pvpanic)
+		**out = **in
+	}
+	if in.RNG != nil {
+		in, out := &in.RNG, &out.RNG
+		*out = new(RNG)
+		**out = **in
+	}
+	if in.Platform != nil {
+		in, out := &in.Platform, &out.Platform
+		*out = new(Platform)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -206,6 +397,11 @@ func (in *Instance) DeepCopy() *Instance {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Interface) DeepCopyInto(out *Interface) {
 	*out = *in
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(InterfaceRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
 	in.InterfaceBindingMethod.DeepCopyInto(&out.InterfaceBindingMethod)
 	return
 }
@@ -293,6 +489,32 @@ func (in *InterfaceMasquerade) DeepCopy() *InterfaceMasquerade {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceRateLimit) DeepCopyInto(out *InterfaceRateLimit) {
+	*out = *in
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BandwidthBurst != nil {
+		in, out := &in.BandwidthBurst, &out.BandwidthBurst
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceRateLimit.
+func (in *InterfaceRateLimit) DeepCopy() *InterfaceRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InterfaceSRIOV) DeepCopyInto(out *InterfaceSRIOV) {
 	*out = *in
@@ -366,6 +588,11 @@ func (in *Memory) DeepCopyInto(out *Memory) {
 		*out = new(Hugepages)
 		**out = **in
 	}
+	if in.TransparentHugepages != nil {
+		in, out := &in.TransparentHugepages, &out.TransparentHugepages
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -395,6 +622,51 @@ func (in *MultusNetworkSource) DeepCopy() *MultusNetworkSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMA) DeepCopyInto(out *NUMA) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NUMANode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMA.
+func (in *NUMA) DeepCopy() *NUMA {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMANode) DeepCopyInto(out *NUMANode) {
+	*out = *in
+	if in.CPUs != nil {
+		in, out := &in.CPUs, &out.CPUs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	out.Memory = in.Memory.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMANode.
+func (in *NUMANode) DeepCopy() *NUMANode {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMANode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -454,6 +726,43 @@ func (in *PersistentVolumeClaimVolumeSource) DeepCopy() *PersistentVolumeClaimVo
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Platform) DeepCopyInto(out *Platform) {
+	*out = *in
+	if in.OEMStrings != nil {
+		in, out := &in.OEMStrings, &out.OEMStrings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Platform.
+func (in *Platform) DeepCopy() *Platform {
+	if in == nil {
+		return nil
+	}
+	out := new(Platform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PmemDevice) DeepCopyInto(out *PmemDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PmemDevice.
+func (in *PmemDevice) DeepCopy() *PmemDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(PmemDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodNetworkSource) DeepCopyInto(out *PodNetworkSource) {
 	*out = *in
@@ -470,6 +779,86 @@ func (in *PodNetworkSource) DeepCopy() *PodNetworkSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Pvpanic) DeepCopyInto(out *Pvpanic) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pvpanic.
+func (in *Pvpanic) DeepCopy() *Pvpanic {
+	if in == nil {
+		return nil
+	}
+	out := new(Pvpanic)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RNG) DeepCopyInto(out *RNG) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RNG.
+func (in *RNG) DeepCopy() *RNG {
+	if in == nil {
+		return nil
+	}
+	out := new(RNG)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountVolumeSource) DeepCopyInto(out *ServiceAccountVolumeSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountVolumeSource.
+func (in *ServiceAccountVolumeSource) DeepCopy() *ServiceAccountVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TPM) DeepCopyInto(out *TPM) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TPM.
+func (in *TPM) DeepCopy() *TPM {
+	if in == nil {
+		return nil
+	}
+	out := new(TPM)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VhostUserVolumeSource) DeepCopyInto(out *VhostUserVolumeSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VhostUserVolumeSource.
+func (in *VhostUserVolumeSource) DeepCopy() *VhostUserVolumeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VhostUserVolumeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VirtualMachine) DeepCopyInto(out *VirtualMachine) {
 	*out = *in
@@ -707,6 +1096,11 @@ func (in *VirtualMachineStatus) DeepCopyInto(out *VirtualMachineStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeMove != nil {
+		in, out := &in.VolumeMove, &out.VolumeMove
+		*out = new(VirtualMachineStatusVolumeMove)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -736,6 +1130,117 @@ func (in *VirtualMachineStatusMigration) DeepCopy() *VirtualMachineStatusMigrati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineStatusVolumeMove) DeepCopyInto(out *VirtualMachineStatusVolumeMove) {
+	*out = *in
+	in.TargetVolume.DeepCopyInto(&out.TargetVolume)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineStatusVolumeMove.
+func (in *VirtualMachineStatusVolumeMove) DeepCopy() *VirtualMachineStatusVolumeMove {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineStatusVolumeMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineVolumeMove) DeepCopyInto(out *VirtualMachineVolumeMove) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeMove.
+func (in *VirtualMachineVolumeMove) DeepCopy() *VirtualMachineVolumeMove {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineVolumeMove)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineVolumeMove) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineVolumeMoveList) DeepCopyInto(out *VirtualMachineVolumeMoveList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VirtualMachineVolumeMove, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeMoveList.
+func (in *VirtualMachineVolumeMoveList) DeepCopy() *VirtualMachineVolumeMoveList {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineVolumeMoveList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VirtualMachineVolumeMoveList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineVolumeMoveSpec) DeepCopyInto(out *VirtualMachineVolumeMoveSpec) {
+	*out = *in
+	in.TargetVolume.DeepCopyInto(&out.TargetVolume)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeMoveSpec.
+func (in *VirtualMachineVolumeMoveSpec) DeepCopy() *VirtualMachineVolumeMoveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineVolumeMoveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualMachineVolumeMoveStatus) DeepCopyInto(out *VirtualMachineVolumeMoveStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineVolumeMoveStatus.
+func (in *VirtualMachineVolumeMoveStatus) DeepCopy() *VirtualMachineVolumeMoveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualMachineVolumeMoveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Volume) DeepCopyInto(out *Volume) {
 	*out = *in
@@ -753,6 +1258,158 @@ func (in *Volume) DeepCopy() *Volume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImport) DeepCopyInto(out *VolumeImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImport.
+func (in *VolumeImport) DeepCopy() *VolumeImport {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportHTTPSource) DeepCopyInto(out *VolumeImportHTTPSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportHTTPSource.
+func (in *VolumeImportHTTPSource) DeepCopy() *VolumeImportHTTPSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportHTTPSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportList) DeepCopyInto(out *VolumeImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportList.
+func (in *VolumeImportList) DeepCopy() *VolumeImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportRegistrySource) DeepCopyInto(out *VolumeImportRegistrySource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportRegistrySource.
+func (in *VolumeImportRegistrySource) DeepCopy() *VolumeImportRegistrySource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportRegistrySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportSource) DeepCopyInto(out *VolumeImportSource) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(VolumeImportHTTPSource)
+		**out = **in
+	}
+	if in.Registry != nil {
+		in, out := &in.Registry, &out.Registry
+		*out = new(VolumeImportRegistrySource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportSource.
+func (in *VolumeImportSource) DeepCopy() *VolumeImportSource {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportSpec) DeepCopyInto(out *VolumeImportSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportSpec.
+func (in *VolumeImportSpec) DeepCopy() *VolumeImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeImportStatus) DeepCopyInto(out *VolumeImportStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeImportStatus.
+func (in *VolumeImportStatus) DeepCopy() *VolumeImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSource) DeepCopyInto(out *VolumeSource) {
 	*out = *in
@@ -781,6 +1438,41 @@ func (in *VolumeSource) DeepCopyInto(out *VolumeSource) {
 		*out = new(DataVolumeVolumeSource)
 		**out = **in
 	}
+	if in.VhostUser != nil {
+		in, out := &in.VhostUser, &out.VhostUser
+		*out = new(VhostUserVolumeSource)
+		**out = **in
+	}
+	if in.EmptyDisk != nil {
+		in, out := &in.EmptyDisk, &out.EmptyDisk
+		*out = new(EmptyDiskVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostDisk != nil {
+		in, out := &in.HostDisk, &out.HostDisk
+		*out = new(HostDiskVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(ServiceAccountVolumeSource)
+		**out = **in
+	}
+	if in.DownwardAPI != nil {
+		in, out := &in.DownwardAPI, &out.DownwardAPI
+		*out = new(v1.DownwardAPIVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -802,6 +1494,16 @@ func (in *VolumeStatus) DeepCopyInto(out *VolumeStatus) {
 		*out = new(HotplugVolumeStatus)
 		**out = **in
 	}
+	if in.ContainerDisk != nil {
+		in, out := &in.ContainerDisk, &out.ContainerDisk
+		*out = new(ContainerDiskVolumeStatus)
+		**out = **in
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
@@ -814,3 +1516,19 @@ func (in *VolumeStatus) DeepCopy() *VolumeStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Watchdog) DeepCopyInto(out *Watchdog) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Watchdog.
+func (in *Watchdog) DeepCopy() *Watchdog {
+	if in == nil {
+		return nil
+	}
+	out := new(Watchdog)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -897,7 +897,7 @@ type MemoryConfig struct {
 
 type MemoryZoneConfig struct {
 	File           string `json:"file,omitempty"`
-	HostNumaNode   int    `json:"host_numa_node,omitempty"`
+	HostNumaNode   *int   `json:"host_numa_node,omitempty"`
 	HotplugSize    int64  `json:"hotplug_size,omitempty"`
 	HotpluggedSize int64  `json:"hotplugged_size,omitempty"`
 	HugepageSize   int64  `json:"hugepage_size,omitempty"`
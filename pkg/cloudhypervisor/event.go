@@ -0,0 +1,8 @@
+package cloudhypervisor
+
+// Event is an event written by Cloud Hypervisor to the file specified with --event-monitor.
+type Event struct {
+	Source     string            `json:"source"`
+	Event      string            `json:"event"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
@@ -0,0 +1,16 @@
+package cloudhypervisor
+
+import "encoding/json"
+
+// MarshalJSON always emits thp, since Cloud Hypervisor enables transparent huge pages when it's omitted,
+// making an omitempty false impossible to express.
+func (c MemoryConfig) MarshalJSON() ([]byte, error) {
+	type memoryConfig MemoryConfig
+	return json.Marshal(struct {
+		memoryConfig
+		Thp bool `json:"thp"`
+	}{
+		memoryConfig: memoryConfig(c),
+		Thp:          c.Thp,
+	})
+}
@@ -0,0 +1,38 @@
+package cloudhypervisor
+
+import (
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+// NewRateLimiterConfig returns a rate limiter refilling bandwidth bytes and ops operations every second, with optional
+// one-time bursts on top of them. A zero bandwidth or ops leaves the corresponding token bucket unlimited.
+func NewRateLimiterConfig(bandwidth int64, bandwidthBurst int64, ops int64, opsBurst int64) *RateLimiterConfig {
+	config := &RateLimiterConfig{}
+	if bandwidth > 0 {
+		config.Bandwidth = &TokenBucket{
+			Size:         bandwidth,
+			OneTimeBurst: bandwidthBurst,
+			RefillTime:   1000,
+		}
+	}
+	if ops > 0 {
+		config.Ops = &TokenBucket{
+			Size:         ops,
+			OneTimeBurst: opsBurst,
+			RefillTime:   1000,
+		}
+	}
+	return config
+}
+
+// NewDiskRateLimiterConfig returns a rate limiter enforcing the IO limits of a disk or a disk IO limit group.
+func NewDiskRateLimiterConfig(limits *virtv1alpha1.DiskIOLimits) *RateLimiterConfig {
+	var bandwidth, bandwidthBurst int64
+	if limits.Bandwidth != nil {
+		bandwidth = limits.Bandwidth.Value()
+	}
+	if limits.BandwidthBurst != nil {
+		bandwidthBurst = limits.BandwidthBurst.Value()
+	}
+	return NewRateLimiterConfig(bandwidth, bandwidthBurst, limits.IOPS, limits.IOPSBurst)
+}
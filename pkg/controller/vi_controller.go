@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/storage/names"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+type VIReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	PrerunnerImageName string
+}
+
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=volumeimports,verbs=get;list;watch
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=volumeimports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;update;patch
+
+func (r *VIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var vi virtv1alpha1.VolumeImport
+	if err := r.Get(ctx, req.NamespacedName, &vi); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status := vi.Status.DeepCopy()
+	rerr := r.reconcile(ctx, &vi)
+
+	if !reflect.DeepEqual(vi.Status, status) {
+		if err := r.Status().Update(ctx, &vi); err != nil {
+			if rerr == nil {
+				if apierrors.IsConflict(err) {
+					return ctrl.Result{Requeue: true}, nil
+				}
+				return ctrl.Result{}, fmt.Errorf("update VI status: %s", err)
+			}
+			if !apierrors.IsConflict(err) {
+				ctrl.LoggerFrom(ctx).Error(err, "update VI status")
+			}
+		}
+	}
+
+	if rerr != nil {
+		reconcileErr := reconcileError{}
+		if errors.As(rerr, &reconcileErr) {
+			return reconcileErr.Result, nil
+		}
+
+		r.Recorder.Eventf(&vi, corev1.EventTypeWarning, "FailedReconcile", "Failed to reconcile VI: %s", rerr)
+		return ctrl.Result{}, rerr
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *VIReconciler) reconcile(ctx context.Context, vi *virtv1alpha1.VolumeImport) error {
+	if vi.DeletionTimestamp != nil && !vi.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	switch vi.Status.Phase {
+	case "":
+		vi.Status.ImporterPodName = names.SimpleNameGenerator.GenerateName(fmt.Sprintf("import-%s-", vi.Name))
+		vi.Status.Phase = virtv1alpha1.VolumeImportPending
+	case virtv1alpha1.VolumeImportPending, virtv1alpha1.VolumeImportRunning:
+		var importerPod corev1.Pod
+		importerPodKey := types.NamespacedName{
+			Name:      vi.Status.ImporterPodName,
+			Namespace: vi.Namespace,
+		}
+		if err := r.Get(ctx, importerPodKey, &importerPod); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("get importer Pod: %s", err)
+			}
+			if vi.Status.Phase == virtv1alpha1.VolumeImportRunning {
+				vi.Status.Phase = virtv1alpha1.VolumeImportFailed
+				return nil
+			}
+
+			importerPod, err := r.buildImporterPod(ctx, vi)
+			if err != nil {
+				return err
+			}
+			importerPod.Name = importerPodKey.Name
+			importerPod.Namespace = importerPodKey.Namespace
+			if err := controllerutil.SetControllerReference(vi, importerPod, r.Scheme); err != nil {
+				return fmt.Errorf("set importer Pod controller reference: %s", err)
+			}
+			if err := r.Create(ctx, importerPod); err != nil {
+				return fmt.Errorf("create importer Pod: %s", err)
+			}
+			r.Recorder.Eventf(vi, corev1.EventTypeNormal, "CreatedImporterPod", "Created importer Pod %q", importerPod.Name)
+			return nil
+		}
+
+		switch importerPod.Status.Phase {
+		case corev1.PodRunning:
+			vi.Status.Phase = virtv1alpha1.VolumeImportRunning
+		case corev1.PodSucceeded:
+			// The importer Pod is deleted to release the PVC for VMs.
+			if err := r.Delete(ctx, &importerPod); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("delete importer Pod: %s", err)
+			}
+			vi.Status.Phase = virtv1alpha1.VolumeImportSucceeded
+		case corev1.PodFailed, corev1.PodUnknown:
+			vi.Status.Phase = virtv1alpha1.VolumeImportFailed
+		default:
+			// ignored
+		}
+	default:
+		// ignored
+	}
+	return nil
+}
+
+func (r *VIReconciler) buildImporterPod(ctx context.Context, vi *virtv1alpha1.VolumeImport) (*corev1.Pod, error) {
+	var pvc corev1.PersistentVolumeClaim
+	pvcKey := types.NamespacedName{
+		Name:      vi.Spec.ClaimName,
+		Namespace: vi.Namespace,
+	}
+	if err := r.Get(ctx, pvcKey, &pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, reconcileError{Result: ctrl.Result{RequeueAfter: time.Minute}}
+		}
+		return nil, fmt.Errorf("get PVC: %s", err)
+	}
+
+	importerPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"virtink.io/volume-import.name": vi.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:    "importer",
+				Image:   r.PrerunnerImageName,
+				Command: []string{"virt-init-volume"},
+				Args:    []string{"import"},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "scratch",
+					MountPath: "/var/lib/virtink/import",
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "scratch",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			}, {
+				Name: "target",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: pvc.Name,
+					},
+				},
+			}},
+		},
+	}
+
+	switch {
+	case vi.Spec.Source.HTTP != nil:
+		importerPod.Spec.Containers[0].Args = append(importerPod.Spec.Containers[0].Args, vi.Spec.Source.HTTP.URL, vi.Spec.Source.HTTP.Checksum)
+	case vi.Spec.Source.Registry != nil:
+		// The disk image is copied out of the containerDisk image, which is based on
+		// virtink-container-disk-base and thus has cp.
+		importerPod.Spec.InitContainers = append(importerPod.Spec.InitContainers, corev1.Container{
+			Name:            "fetch",
+			Image:           vi.Spec.Source.Registry.Image,
+			ImagePullPolicy: vi.Spec.Source.Registry.ImagePullPolicy,
+			Command:         []string{"cp", "/disk", "/var/lib/virtink/import/disk"},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "scratch",
+				MountPath: "/var/lib/virtink/import",
+			}},
+		})
+		importerPod.Spec.Containers[0].Args = append(importerPod.Spec.Containers[0].Args, "/var/lib/virtink/import/disk", vi.Spec.Source.Registry.Checksum)
+	default:
+		return nil, fmt.Errorf("invalid source of VI %q", vi.Name)
+	}
+
+	if pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		importerPod.Spec.Containers[0].VolumeDevices = append(importerPod.Spec.Containers[0].VolumeDevices, corev1.VolumeDevice{
+			Name:       "target",
+			DevicePath: "/mnt/target",
+		})
+		importerPod.Spec.Containers[0].Args = append(importerPod.Spec.Containers[0].Args, "/mnt/target")
+	} else {
+		importerPod.Spec.Containers[0].VolumeMounts = append(importerPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "target",
+			MountPath: "/mnt/target",
+		})
+		importerPod.Spec.Containers[0].Args = append(importerPod.Spec.Containers[0].Args, "/mnt/target/disk.img")
+	}
+	return &importerPod, nil
+}
+
+func (r *VIReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&virtv1alpha1.VolumeImport{}).
+		Owns(&corev1.Pod{}).
+		Complete(r)
+}
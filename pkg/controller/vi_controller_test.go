@@ -0,0 +1,297 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+func TestReconcileVI(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(virtv1alpha1.AddToScheme(scheme))
+
+	validVI := &virtv1alpha1.VolumeImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-vi",
+			Namespace: "default",
+			UID:       "test-vi-uid",
+		},
+		Spec: virtv1alpha1.VolumeImportSpec{
+			Source: virtv1alpha1.VolumeImportSource{
+				HTTP: &virtv1alpha1.VolumeImportHTTPSource{
+					URL: "https://cloud-images.ubuntu.com/jammy/current/jammy-server-cloudimg-amd64.img",
+				},
+			},
+			ClaimName: "test-pvc",
+		},
+	}
+
+	validPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pvc",
+			Namespace: "default",
+		},
+	}
+
+	viInPhase := func(phase virtv1alpha1.VolumeImportPhase) *virtv1alpha1.VolumeImport {
+		vi := validVI.DeepCopy()
+		vi.Status.Phase = phase
+		vi.Status.ImporterPodName = "import-test-vi-abcde"
+		return vi
+	}
+
+	importerPodInPhase := func(phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "import-test-vi-abcde",
+				Namespace: "default",
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+			},
+		}
+	}
+
+	tests := []struct {
+		vi          *virtv1alpha1.VolumeImport
+		pvc         *corev1.PersistentVolumeClaim
+		importerPod *corev1.Pod
+
+		expectedRequeueAfter      time.Duration
+		expectedPhase             virtv1alpha1.VolumeImportPhase
+		expectedImporterPodExists bool
+	}{{
+		vi:            validVI,
+		expectedPhase: virtv1alpha1.VolumeImportPending,
+	}, {
+		vi:                        viInPhase(virtv1alpha1.VolumeImportPending),
+		pvc:                       validPVC,
+		expectedPhase:             virtv1alpha1.VolumeImportPending,
+		expectedImporterPodExists: true,
+	}, {
+		vi:                   viInPhase(virtv1alpha1.VolumeImportPending),
+		expectedRequeueAfter: time.Minute,
+		expectedPhase:        virtv1alpha1.VolumeImportPending,
+	}, {
+		vi:                        viInPhase(virtv1alpha1.VolumeImportPending),
+		pvc:                       validPVC,
+		importerPod:               importerPodInPhase(corev1.PodPending),
+		expectedPhase:             virtv1alpha1.VolumeImportPending,
+		expectedImporterPodExists: true,
+	}, {
+		vi:                        viInPhase(virtv1alpha1.VolumeImportPending),
+		pvc:                       validPVC,
+		importerPod:               importerPodInPhase(corev1.PodRunning),
+		expectedPhase:             virtv1alpha1.VolumeImportRunning,
+		expectedImporterPodExists: true,
+	}, {
+		vi:            viInPhase(virtv1alpha1.VolumeImportRunning),
+		pvc:           validPVC,
+		importerPod:   importerPodInPhase(corev1.PodSucceeded),
+		expectedPhase: virtv1alpha1.VolumeImportSucceeded,
+	}, {
+		vi:                        viInPhase(virtv1alpha1.VolumeImportRunning),
+		pvc:                       validPVC,
+		importerPod:               importerPodInPhase(corev1.PodFailed),
+		expectedPhase:             virtv1alpha1.VolumeImportFailed,
+		expectedImporterPodExists: true,
+	}, {
+		vi:            viInPhase(virtv1alpha1.VolumeImportRunning),
+		pvc:           validPVC,
+		expectedPhase: virtv1alpha1.VolumeImportFailed,
+	}, {
+		vi:            viInPhase(virtv1alpha1.VolumeImportSucceeded),
+		pvc:           validPVC,
+		expectedPhase: virtv1alpha1.VolumeImportSucceeded,
+	}}
+
+	for i, tc := range tests {
+		objs := []client.Object{tc.vi.DeepCopy()}
+		if tc.pvc != nil {
+			objs = append(objs, tc.pvc.DeepCopy())
+		}
+		if tc.importerPod != nil {
+			objs = append(objs, tc.importerPod.DeepCopy())
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&virtv1alpha1.VolumeImport{}).Build()
+		r := &VIReconciler{
+			Client:             c,
+			Scheme:             scheme,
+			Recorder:           record.NewFakeRecorder(10),
+			PrerunnerImageName: "smartxworks/virt-prerunner",
+		}
+
+		var vi virtv1alpha1.VolumeImport
+		assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(tc.vi), &vi), "case %d", i)
+		err := r.reconcile(context.Background(), &vi)
+		if tc.expectedRequeueAfter != 0 {
+			reconcileErr := reconcileError{}
+			if assert.True(t, errors.As(err, &reconcileErr), "case %d", i) {
+				assert.Equal(t, ctrl.Result{RequeueAfter: tc.expectedRequeueAfter}, reconcileErr.Result, "case %d", i)
+			}
+		} else {
+			assert.NoError(t, err, "case %d", i)
+		}
+		assert.Equal(t, tc.expectedPhase, vi.Status.Phase, "case %d", i)
+		if vi.Status.Phase != "" {
+			assert.NotEmpty(t, vi.Status.ImporterPodName, "case %d", i)
+		}
+
+		var importerPod corev1.Pod
+		err = c.Get(context.Background(), types.NamespacedName{Name: vi.Status.ImporterPodName, Namespace: vi.Namespace}, &importerPod)
+		if tc.expectedImporterPodExists {
+			if assert.NoError(t, err, "case %d", i) && tc.importerPod == nil {
+				if controllerRef := metav1.GetControllerOf(&importerPod); assert.NotNil(t, controllerRef, "case %d", i) {
+					assert.Equal(t, vi.UID, controllerRef.UID, "case %d", i)
+				}
+			}
+		} else {
+			assert.True(t, apierrors.IsNotFound(err), "case %d", i)
+		}
+	}
+}
+
+func TestBuildImporterPod(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(virtv1alpha1.AddToScheme(scheme))
+
+	httpVI := &virtv1alpha1.VolumeImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-vi",
+			Namespace: "default",
+		},
+		Spec: virtv1alpha1.VolumeImportSpec{
+			Source: virtv1alpha1.VolumeImportSource{
+				HTTP: &virtv1alpha1.VolumeImportHTTPSource{
+					URL:      "https://example.com/disk.img",
+					Checksum: "sha256:abcdef",
+				},
+			},
+			ClaimName: "test-pvc",
+		},
+	}
+
+	registryVI := httpVI.DeepCopy()
+	registryVI.Spec.Source = virtv1alpha1.VolumeImportSource{
+		Registry: &virtv1alpha1.VolumeImportRegistrySource{
+			Image:           "smartxworks/virtink-container-disk-ubuntu",
+			ImagePullPolicy: corev1.PullAlways,
+		},
+	}
+
+	filesystemPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pvc",
+			Namespace: "default",
+		},
+	}
+
+	blockPVC := filesystemPVC.DeepCopy()
+	volumeMode := corev1.PersistentVolumeBlock
+	blockPVC.Spec.VolumeMode = &volumeMode
+
+	tests := []struct {
+		vi  *virtv1alpha1.VolumeImport
+		pvc *corev1.PersistentVolumeClaim
+
+		expectedArgs           []string
+		expectedInitContainers []corev1.Container
+		expectedVolumeMounts   []corev1.VolumeMount
+		expectedVolumeDevices  []corev1.VolumeDevice
+	}{{
+		vi:           httpVI,
+		pvc:          filesystemPVC,
+		expectedArgs: []string{"import", "https://example.com/disk.img", "sha256:abcdef", "/mnt/target/disk.img"},
+		expectedVolumeMounts: []corev1.VolumeMount{{
+			Name:      "scratch",
+			MountPath: "/var/lib/virtink/import",
+		}, {
+			Name:      "target",
+			MountPath: "/mnt/target",
+		}},
+	}, {
+		vi:           httpVI,
+		pvc:          blockPVC,
+		expectedArgs: []string{"import", "https://example.com/disk.img", "sha256:abcdef", "/mnt/target"},
+		expectedVolumeMounts: []corev1.VolumeMount{{
+			Name:      "scratch",
+			MountPath: "/var/lib/virtink/import",
+		}},
+		expectedVolumeDevices: []corev1.VolumeDevice{{
+			Name:       "target",
+			DevicePath: "/mnt/target",
+		}},
+	}, {
+		vi:           registryVI,
+		pvc:          filesystemPVC,
+		expectedArgs: []string{"import", "/var/lib/virtink/import/disk", "", "/mnt/target/disk.img"},
+		expectedInitContainers: []corev1.Container{{
+			Name:            "fetch",
+			Image:           "smartxworks/virtink-container-disk-ubuntu",
+			ImagePullPolicy: corev1.PullAlways,
+			Command:         []string{"cp", "/disk", "/var/lib/virtink/import/disk"},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "scratch",
+				MountPath: "/var/lib/virtink/import",
+			}},
+		}},
+		expectedVolumeMounts: []corev1.VolumeMount{{
+			Name:      "scratch",
+			MountPath: "/var/lib/virtink/import",
+		}, {
+			Name:      "target",
+			MountPath: "/mnt/target",
+		}},
+	}}
+
+	for i, tc := range tests {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.pvc.DeepCopy()).Build()
+		r := &VIReconciler{
+			Client:             c,
+			Scheme:             scheme,
+			PrerunnerImageName: "smartxworks/virt-prerunner",
+		}
+
+		importerPod, err := r.buildImporterPod(context.Background(), tc.vi)
+		if !assert.NoError(t, err, "case %d", i) {
+			continue
+		}
+		assert.Equal(t, corev1.RestartPolicyNever, importerPod.Spec.RestartPolicy, "case %d", i)
+		assert.Equal(t, tc.expectedInitContainers, importerPod.Spec.InitContainers, "case %d", i)
+		if assert.Len(t, importerPod.Spec.Containers, 1, "case %d", i) {
+			container := importerPod.Spec.Containers[0]
+			assert.Equal(t, "smartxworks/virt-prerunner", container.Image, "case %d", i)
+			assert.Equal(t, []string{"virt-init-volume"}, container.Command, "case %d", i)
+			assert.Equal(t, tc.expectedArgs, container.Args, "case %d", i)
+			assert.Equal(t, tc.expectedVolumeMounts, container.VolumeMounts, "case %d", i)
+			assert.Equal(t, tc.expectedVolumeDevices, container.VolumeDevices, "case %d", i)
+		}
+		assert.Contains(t, importerPod.Spec.Volumes, corev1.Volume{
+			Name: "target",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "test-pvc",
+				},
+			},
+		}, "case %d", i)
+	}
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-v1alpha1-volumeimport,mutating=false,failurePolicy=fail,sideEffects=None,groups=virt.virtink.smartx.com,resources=volumeimports,verbs=create;update,versions=v1alpha1,name=validate.volumeimport.v1alpha1.virt.virtink.smartx.com,admissionReviewVersions={v1,v1beta1}
+
+type VIValidator struct {
+	decoder admission.Decoder
+}
+
+var _ admission.Handler = &VIValidator{}
+
+func (h *VIValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	h.decoder = admission.NewDecoder(mgr.GetScheme())
+
+	mgr.GetWebhookServer().Register("/validate-v1alpha1-volumeimport", &webhook.Admission{
+		Handler: h,
+	})
+	return nil
+}
+
+func (h *VIValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var vi virtv1alpha1.VolumeImport
+	if err := h.decoder.Decode(req, &vi); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unmarshal VI: %s", err))
+	}
+
+	var errs field.ErrorList
+	switch req.Operation {
+	case admissionv1.Create:
+		errs = ValidateVI(ctx, &vi, nil)
+	case admissionv1.Update:
+		var oldVI virtv1alpha1.VolumeImport
+		if err := h.decoder.DecodeRaw(req.OldObject, &oldVI); err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf("unmarshal old VI: %s", err))
+		}
+		errs = ValidateVI(ctx, &vi, &oldVI)
+	default:
+		return admission.Allowed("")
+	}
+
+	if len(errs) > 0 {
+		return webhook.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+func ValidateVI(ctx context.Context, vi *virtv1alpha1.VolumeImport, oldVI *virtv1alpha1.VolumeImport) field.ErrorList {
+	var errs field.ErrorList
+	if oldVI != nil {
+		if !reflect.DeepEqual(vi.Spec, oldVI.Spec) {
+			errs = append(errs, field.Forbidden(field.NewPath("spec"), "VI spec may not be updated"))
+		}
+		return errs
+	}
+	errs = append(errs, ValidateVISpec(ctx, &vi.Spec, field.NewPath("spec"))...)
+	return errs
+}
+
+func ValidateVISpec(ctx context.Context, spec *virtv1alpha1.VolumeImportSpec, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if spec == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	errs = append(errs, ValidateVISource(ctx, &spec.Source, fieldPath.Child("source"))...)
+	if spec.ClaimName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("claimName"), ""))
+	}
+	return errs
+}
+
+func ValidateVISource(ctx context.Context, source *virtv1alpha1.VolumeImportSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	cnt := 0
+	if source.HTTP != nil {
+		cnt++
+		errs = append(errs, ValidateVIHTTPSource(ctx, source.HTTP, fieldPath.Child("http"))...)
+	}
+	if source.Registry != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("registry"), "may not specify more than 1 source"))
+		} else {
+			errs = append(errs, ValidateVIRegistrySource(ctx, source.Registry, fieldPath.Child("registry"))...)
+		}
+	}
+	if cnt == 0 {
+		errs = append(errs, field.Required(fieldPath, "at least 1 source is required"))
+	}
+	return errs
+}
+
+func ValidateVIHTTPSource(ctx context.Context, source *virtv1alpha1.VolumeImportHTTPSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.URL == "" {
+		errs = append(errs, field.Required(fieldPath.Child("url"), ""))
+	} else if u, err := url.Parse(source.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, field.Invalid(fieldPath.Child("url"), source.URL, "must be an HTTP or HTTPS URL"))
+	}
+	errs = append(errs, ValidateVIChecksum(ctx, source.Checksum, fieldPath.Child("checksum"))...)
+	return errs
+}
+
+func ValidateVIRegistrySource(ctx context.Context, source *virtv1alpha1.VolumeImportRegistrySource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.Image == "" {
+		errs = append(errs, field.Required(fieldPath.Child("image"), ""))
+	}
+	errs = append(errs, ValidateVIChecksum(ctx, source.Checksum, fieldPath.Child("checksum"))...)
+	return errs
+}
+
+var checksumRegexp = regexp.MustCompile(`^(sha256:[0-9a-f]{64}|sha512:[0-9a-f]{128})$`)
+
+func ValidateVIChecksum(ctx context.Context, checksum string, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if checksum != "" && !checksumRegexp.MatchString(checksum) {
+		errs = append(errs, field.Invalid(fieldPath, checksum, "must be in the form of sha256:<hex> or sha512:<hex>"))
+	}
+	return errs
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+func TestValidateVI(t *testing.T) {
+	validVI := &virtv1alpha1.VolumeImport{
+		Spec: virtv1alpha1.VolumeImportSpec{
+			Source: virtv1alpha1.VolumeImportSource{
+				HTTP: &virtv1alpha1.VolumeImportHTTPSource{
+					URL:      "https://cloud-images.ubuntu.com/jammy/current/jammy-server-cloudimg-amd64.img",
+					Checksum: "sha256:bd3d1b2bdcb0c5b6e3bd7e4f5a5d2e81d2aa2e28aa1b0f5a7d4b3c3e1e4f5a6b",
+				},
+			},
+			ClaimName: "ubuntu",
+		},
+	}
+
+	tests := []struct {
+		vi            *virtv1alpha1.VolumeImport
+		oldVI         *virtv1alpha1.VolumeImport
+		invalidFields []string
+	}{{
+		vi: validVI,
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source = virtv1alpha1.VolumeImportSource{
+				Registry: &virtv1alpha1.VolumeImportRegistrySource{
+					Image: "smartxworks/virtink-container-disk-ubuntu",
+				},
+			}
+			return vi
+		}(),
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.ClaimName = ""
+			return vi
+		}(),
+		invalidFields: []string{"spec.claimName"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source = virtv1alpha1.VolumeImportSource{}
+			return vi
+		}(),
+		invalidFields: []string{"spec.source"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source.Registry = &virtv1alpha1.VolumeImportRegistrySource{
+				Image: "smartxworks/virtink-container-disk-ubuntu",
+			}
+			return vi
+		}(),
+		invalidFields: []string{"spec.source.registry"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source.HTTP.URL = "ftp://example.com/disk.img"
+			return vi
+		}(),
+		invalidFields: []string{"spec.source.http.url"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source.HTTP.Checksum = "md5:d41d8cd98f00b204e9800998ecf8427e"
+			return vi
+		}(),
+		invalidFields: []string{"spec.source.http.checksum"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.Source = virtv1alpha1.VolumeImportSource{
+				Registry: &virtv1alpha1.VolumeImportRegistrySource{},
+			}
+			return vi
+		}(),
+		invalidFields: []string{"spec.source.registry.image"},
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Labels = map[string]string{"foo": "bar"}
+			return vi
+		}(),
+		oldVI: validVI,
+	}, {
+		vi: func() *virtv1alpha1.VolumeImport {
+			vi := validVI.DeepCopy()
+			vi.Spec.ClaimName = "ubuntu-2"
+			return vi
+		}(),
+		oldVI:         validVI,
+		invalidFields: []string{"spec"},
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateVI(context.Background(), tc.vi, tc.oldVI)
+		if len(tc.invalidFields) == 0 {
+			assert.Empty(t, errs)
+		}
+		for _, err := range errs {
+			assert.Contains(t, tc.invalidFields, err.Field)
+		}
+	}
+}
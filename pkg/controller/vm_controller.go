@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/daemon/imagecache"
 	"github.com/smartxworks/virtink/pkg/volumeutil"
 )
 
@@ -199,6 +201,7 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 			default:
 				// ignored
 			}
+			updateContainerDiskVolumeStatus(vm, &vmPod)
 			if err := r.updateHotplugVolumeStatus(ctx, vm, &vmPod); err != nil {
 				return err
 			}
@@ -317,6 +320,14 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 				}
 			}
 		} else {
+			if vm.Status.VolumeMove != nil {
+				switch vm.Status.VolumeMove.Phase {
+				case "", virtv1alpha1.VirtualMachineVolumeMovePending:
+					vm.Status.VolumeMove.TargetVolume.Name = names.SimpleNameGenerator.GenerateName(fmt.Sprintf("%s-", vm.Status.VolumeMove.VolumeName))
+					vm.Status.VolumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveAttaching
+				}
+			}
+
 			if err := r.handleHotplugVolumes(ctx, vm, &vmPod, false); err != nil {
 				return err
 			}
@@ -413,6 +424,7 @@ func (r *VMReconciler) buildVMPod(ctx context.Context, vm *virtv1alpha1.VirtualM
 	}
 	vmPod.Labels["virtink.io/vm.name"] = vm.Name
 
+	useImageCache := false
 	if vm.Spec.Instance.Kernel != nil {
 		vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
 			Name: "virtink-kernel",
@@ -427,13 +439,64 @@ func (r *VMReconciler) buildVMPod(ctx context.Context, vm *virtv1alpha1.VirtualM
 		}
 		vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, volumeMount)
 
-		vmPod.Spec.InitContainers = append(vmPod.Spec.InitContainers, corev1.Container{
+		initContainer := corev1.Container{
 			Name:            "init-kernel",
 			Image:           vm.Spec.Instance.Kernel.Image,
 			ImagePullPolicy: vm.Spec.Instance.Kernel.ImagePullPolicy,
 			Resources:       vm.Spec.Resources,
 			Args:            []string{volumeMount.MountPath + "/vmlinux"},
 			VolumeMounts:    []corev1.VolumeMount{volumeMount},
+		}
+		vmPod.Spec.InitContainers = append(vmPod.Spec.InitContainers, initContainer)
+		useImageCache = true
+	}
+
+	if vm.Spec.Instance.Firmware != nil && vm.Spec.Instance.Firmware.Image != "" {
+		vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+			Name: "virtink-firmware",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+
+		volumeMount := corev1.VolumeMount{
+			Name:      "virtink-firmware",
+			MountPath: "/mnt/virtink-firmware",
+		}
+		vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, volumeMount)
+
+		vmPod.Spec.InitContainers = append(vmPod.Spec.InitContainers, corev1.Container{
+			Name:            "init-firmware",
+			Image:           vm.Spec.Instance.Firmware.Image,
+			ImagePullPolicy: vm.Spec.Instance.Firmware.ImagePullPolicy,
+			Resources:       vm.Spec.Resources,
+			Args:            []string{volumeMount.MountPath + "/firmware"},
+			VolumeMounts:    []corev1.VolumeMount{volumeMount},
+		})
+	}
+
+	if vm.Spec.Instance.Firmware != nil && vm.Spec.Instance.Firmware.Type == virtv1alpha1.FirmwareRustHypervisorFirmware {
+		// rust-hypervisor-firmware is not available for arm64
+		nodeSelector := map[string]string{}
+		for key, value := range vmPod.Spec.NodeSelector {
+			nodeSelector[key] = value
+		}
+		nodeSelector[corev1.LabelArchStable] = "amd64"
+		vmPod.Spec.NodeSelector = nodeSelector
+	}
+
+	if vm.Spec.Instance.TPM != nil && vm.Spec.Instance.TPM.ClaimName != "" {
+		vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+			Name: "virtink-tpm",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: vm.Spec.Instance.TPM.ClaimName,
+				},
+			},
+		})
+		vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "virtink-tpm",
+			MountPath: "/var/lib/virtink/tpm",
 		})
 	}
 
@@ -454,6 +517,9 @@ func (r *VMReconciler) buildVMPod(ctx context.Context, vm *virtv1alpha1.VirtualM
 	}
 
 	blockVolumes := []string{}
+	var emptyDisksCapacity resource.Quantity
+	useKubernetesObjectISO := false
+	var hostDiskNodeName string
 	for _, volume := range vm.Spec.Volumes {
 		switch {
 		case volume.ContainerDisk != nil:
@@ -470,14 +536,29 @@ func (r *VMReconciler) buildVMPod(ctx context.Context, vm *virtv1alpha1.VirtualM
 			}
 			vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, volumeMount)
 
-			vmPod.Spec.InitContainers = append(vmPod.Spec.InitContainers, corev1.Container{
+			initContainer := corev1.Container{
 				Name:            "init-volume-" + volume.Name,
 				Image:           volume.ContainerDisk.Image,
 				ImagePullPolicy: volume.ContainerDisk.ImagePullPolicy,
 				Resources:       vm.Spec.Resources,
-				Args:            []string{volumeMount.MountPath + "/disk.raw"},
+				Args:            []string{volumeMount.MountPath + "/disk.qcow2"},
 				VolumeMounts:    []corev1.VolumeMount{volumeMount},
-			})
+			}
+
+			// The disk is shared in the image cache as the backing file of a QCOW2 overlay, except for
+			// pmem devices which map the disk into the guest as is and thus require a private raw disk.
+			isPmem := false
+			for _, pmem := range vm.Spec.Instance.PmemDevices {
+				if pmem.Name == volume.Name {
+					isPmem = true
+				}
+			}
+			if isPmem {
+				initContainer.Args = []string{volumeMount.MountPath + "/disk.raw"}
+			} else {
+				useImageCache = true
+			}
+			vmPod.Spec.InitContainers = append(vmPod.Spec.InitContainers, initContainer)
 		case volume.CloudInit != nil:
 			initContainer := corev1.Container{
 				Name:      "init-volume-" + volume.Name,
@@ -618,10 +699,191 @@ func (r *VMReconciler) buildVMPod(ctx context.Context, vm *virtv1alpha1.VirtualM
 					vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, volumeMount)
 				}
 			}
+		case volume.EmptyDisk != nil:
+			vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+				Name: volume.Name,
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{
+						SizeLimit: &volume.EmptyDisk.Capacity,
+					},
+				},
+			})
+			vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: "/mnt/" + volume.Name,
+			})
+			emptyDisksCapacity.Add(volume.EmptyDisk.Capacity)
+		case volume.ConfigMap != nil, volume.Secret != nil, volume.ServiceAccount != nil, volume.DownwardAPI != nil:
+			podVolume := corev1.Volume{
+				Name: volume.Name,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap:   volume.ConfigMap,
+					Secret:      volume.Secret,
+					DownwardAPI: volume.DownwardAPI,
+				},
+			}
+			if volume.ServiceAccount != nil {
+				vmPod.Spec.ServiceAccountName = volume.ServiceAccount.ServiceAccountName
+				// the same files as the service account volume mounted by kubelet
+				podVolume.Projected = &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Path: "token",
+						},
+					}, {
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: "kube-root-ca.crt",
+							},
+							Items: []corev1.KeyToPath{{
+								Key:  "ca.crt",
+								Path: "ca.crt",
+							}},
+						},
+					}, {
+						DownwardAPI: &corev1.DownwardAPIProjection{
+							Items: []corev1.DownwardAPIVolumeFile{{
+								Path: "namespace",
+								FieldRef: &corev1.ObjectFieldSelector{
+									APIVersion: "v1",
+									FieldPath:  "metadata.namespace",
+								},
+							}},
+						},
+					}},
+				}
+			}
+			vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, podVolume)
+			vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: "/mnt/" + volume.Name,
+				ReadOnly:  true,
+			})
+			for _, disk := range vm.Spec.Instance.Disks {
+				if disk.Name == volume.Name {
+					useKubernetesObjectISO = true
+				}
+			}
+		case volume.HostDisk != nil:
+			hostPathType := corev1.HostPathFile
+			if volume.HostDisk.Type == virtv1alpha1.HostDiskExistsOrCreate {
+				hostPathType = corev1.HostPathFileOrCreate
+			}
+			vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+				Name: volume.Name,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: volume.HostDisk.Path,
+						Type: &hostPathType,
+					},
+				},
+			})
+			vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+				Name:      volume.Name,
+				MountPath: "/mnt/" + volume.Name + "/disk.img",
+			})
+			hostDiskNodeName = volume.HostDisk.NodeName
+		case volume.VhostUser != nil:
+			if volume.VhostUser.HostPath != "" {
+				// mount the directory instead of the socket, so that a socket recreated by a restarted backend is
+				// still reachable
+				vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+					Name: volume.Name,
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: filepath.Dir(filepath.Clean(volume.VhostUser.HostPath)),
+							Type: &[]corev1.HostPathType{corev1.HostPathDirectory}[0],
+						},
+					},
+				})
+				vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+					Name:      volume.Name,
+					MountPath: "/mnt/" + volume.Name,
+				})
+			} else {
+				vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+					Name: volume.Name,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: volume.VhostUser.ClaimName,
+						},
+					},
+				})
+				vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+					Name:      volume.Name,
+					MountPath: "/mnt/" + volume.Name,
+				})
+			}
 		default:
 			// ignored
 		}
 	}
+	if hostDiskNodeName != "" {
+		affinity := vm.Spec.Affinity.DeepCopy()
+		if affinity == nil {
+			affinity = &corev1.Affinity{}
+		}
+		if affinity.NodeAffinity == nil {
+			affinity.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		nodeAffinity := affinity.NodeAffinity
+		if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+		}
+		nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if len(nodeSelector.NodeSelectorTerms) == 0 {
+			nodeSelector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+		}
+		// node selector terms are ORed, so the node requirement is added to each of them
+		for i := range nodeSelector.NodeSelectorTerms {
+			nodeSelector.NodeSelectorTerms[i].MatchExpressions = append(nodeSelector.NodeSelectorTerms[i].MatchExpressions, corev1.NodeSelectorRequirement{
+				Key:      "kubernetes.io/hostname",
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{hostDiskNodeName},
+			})
+		}
+		vmPod.Spec.Affinity = affinity
+	}
+
+	if !emptyDisksCapacity.IsZero() {
+		addContainerResource(&vmPod.Spec.Containers[0], corev1.ResourceEphemeralStorage, emptyDisksCapacity)
+	}
+
+	if useKubernetesObjectISO {
+		// ISO images may contain secrets, which are kept off the node's disk like Secret volumes are
+		vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+			Name: "virtink-iso",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				},
+			},
+		})
+		vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "virtink-iso",
+			MountPath: "/var/run/virtink/iso",
+		})
+	}
+
+	if useImageCache {
+		// Init containers run user images, so only the VM container has access to the cache, which is
+		// read-only. virt-daemon tells init containers whether their images are cached through their volumes.
+		vmPod.Spec.Volumes = append(vmPod.Spec.Volumes, corev1.Volume{
+			Name: "virtink-image-cache",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: imagecache.DefaultDir,
+					Type: &[]corev1.HostPathType{corev1.HostPathDirectoryOrCreate}[0],
+				},
+			},
+		})
+		vmPod.Spec.Containers[0].VolumeMounts = append(vmPod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "virtink-image-cache",
+			MountPath: imagecache.DefaultDir,
+			ReadOnly:  true,
+		})
+	}
+
 	if len(blockVolumes) > 0 {
 		vmPod.Spec.Containers[0].Env = append(vmPod.Spec.Containers[0].Env, corev1.EnvVar{Name: "BLOCK_VOLUMES", Value: strings.Join(blockVolumes, ",")})
 	}
@@ -884,6 +1146,14 @@ func getHotplugVolumes(vm *virtv1alpha1.VirtualMachine, vmPod *corev1.Pod) []*vi
 			hotplugVolumes = append(hotplugVolumes, volume)
 		}
 	}
+
+	if vm.Status.VolumeMove != nil {
+		switch vm.Status.VolumeMove.Phase {
+		case virtv1alpha1.VirtualMachineVolumeMoveAttaching, virtv1alpha1.VirtualMachineVolumeMoveCopying,
+			virtv1alpha1.VirtualMachineVolumeMoveSwitching, virtv1alpha1.VirtualMachineVolumeMoveSwitched:
+			hotplugVolumes = append(hotplugVolumes, &vm.Status.VolumeMove.TargetVolume)
+		}
+	}
 	return hotplugVolumes
 }
 
@@ -918,7 +1188,9 @@ func isPodMatchVMHotplugVolumes(pod *corev1.Pod, hotplugVolumes []*virtv1alpha1.
 		}
 	}
 	for _, volume := range hotplugVolumes {
-		delete(podVolumesMap, volume.Name)
+		if podVolume, ok := podVolumesMap[volume.Name]; ok && podVolume.PersistentVolumeClaim.ClaimName == volume.PVCName() {
+			delete(podVolumesMap, volume.Name)
+		}
 	}
 	return len(podVolumesMap) == 0
 }
@@ -1008,6 +1280,42 @@ func (r *VMReconciler) buildHotplugVolumePod(ctx context.Context, vm *virtv1alph
 	return pod, nil
 }
 
+// updateContainerDiskVolumeStatus reports the image formats of containerDisk volumes, which are
+// written to the termination messages of the init containers.
+func updateContainerDiskVolumeStatus(vm *virtv1alpha1.VirtualMachine, vmPod *corev1.Pod) {
+	for _, volume := range vm.Spec.Volumes {
+		if volume.ContainerDisk == nil {
+			continue
+		}
+		for _, containerStatus := range vmPod.Status.InitContainerStatuses {
+			if containerStatus.Name != "init-volume-"+volume.Name {
+				continue
+			}
+			terminated := containerStatus.State.Terminated
+			if terminated == nil || terminated.ExitCode != 0 || terminated.Message == "" {
+				continue
+			}
+
+			var volumeStatus *virtv1alpha1.VolumeStatus
+			for i := range vm.Status.VolumeStatus {
+				if vm.Status.VolumeStatus[i].Name == volume.Name {
+					volumeStatus = &vm.Status.VolumeStatus[i]
+				}
+			}
+			if volumeStatus == nil {
+				vm.Status.VolumeStatus = append(vm.Status.VolumeStatus, virtv1alpha1.VolumeStatus{
+					Name: volume.Name,
+				})
+				volumeStatus = &vm.Status.VolumeStatus[len(vm.Status.VolumeStatus)-1]
+			}
+			volumeStatus.Phase = virtv1alpha1.VolumeReady
+			volumeStatus.ContainerDisk = &virtv1alpha1.ContainerDiskVolumeStatus{
+				Format: strings.TrimSpace(terminated.Message),
+			}
+		}
+	}
+}
+
 func (r *VMReconciler) updateHotplugVolumeStatus(ctx context.Context, vm *virtv1alpha1.VirtualMachine, vmPod *corev1.Pod) error {
 	hotplugVolumes := getHotplugVolumes(vm, vmPod)
 	volumeStatusMap := map[string]virtv1alpha1.VolumeStatus{}
@@ -1019,7 +1327,21 @@ func (r *VMReconciler) updateHotplugVolumeStatus(ctx context.Context, vm *virtv1
 		return err
 	}
 
+	// Statuses of volumes attached to the VM Pod, such as the format of a containerDisk or the capacity of a PVC, are
+	// reported elsewhere and kept as is.
+	podVolumes := map[string]bool{}
+	for _, volume := range vm.Spec.Volumes {
+		if !volume.IsHotpluggable() {
+			podVolumes[volume.Name] = true
+		}
+	}
 	newVolumeStatus := []virtv1alpha1.VolumeStatus{}
+	for _, status := range vm.Status.VolumeStatus {
+		if status.HotplugVolume == nil && podVolumes[status.Name] {
+			newVolumeStatus = append(newVolumeStatus, status)
+			delete(volumeStatusMap, status.Name)
+		}
+	}
 	for _, volume := range hotplugVolumes {
 		status := virtv1alpha1.VolumeStatus{}
 		if _, ok := volumeStatusMap[volume.Name]; ok {
@@ -1176,6 +1498,48 @@ func (r *VMReconciler) calculateMigratableCondition(ctx context.Context, vm *vir
 				Message: "migration is disabled when VM has a containerDisk volume",
 			}, nil
 		}
+		if volume.VhostUser != nil {
+			return &metav1.Condition{
+				Type:    string(virtv1alpha1.VirtualMachineMigratable),
+				Status:  metav1.ConditionFalse,
+				Reason:  "VolumeNotMigratable",
+				Message: "migration is disabled when VM has a vhostUser volume",
+			}, nil
+		}
+		if volume.EmptyDisk != nil {
+			return &metav1.Condition{
+				Type:    string(virtv1alpha1.VirtualMachineMigratable),
+				Status:  metav1.ConditionFalse,
+				Reason:  "VolumeNotMigratable",
+				Message: "migration is disabled when VM has an emptyDisk volume",
+			}, nil
+		}
+		if volume.HostDisk != nil {
+			return &metav1.Condition{
+				Type:    string(virtv1alpha1.VirtualMachineMigratable),
+				Status:  metav1.ConditionFalse,
+				Reason:  "VolumeNotMigratable",
+				Message: "migration is disabled when VM has a hostDisk volume",
+			}, nil
+		}
+	}
+
+	if vm.Spec.Instance.TPM != nil {
+		return &metav1.Condition{
+			Type:    string(virtv1alpha1.VirtualMachineMigratable),
+			Status:  metav1.ConditionFalse,
+			Reason:  "TPMNotMigratable",
+			Message: "migration is disabled when VM has a TPM",
+		}, nil
+	}
+
+	if len(vm.Spec.Instance.PmemDevices) > 0 {
+		return &metav1.Condition{
+			Type:    string(virtv1alpha1.VirtualMachineMigratable),
+			Status:  metav1.ConditionFalse,
+			Reason:  "PmemNotMigratable",
+			Message: "migration is disabled when VM has a pmem device",
+		}, nil
 	}
 
 	if len(vm.Spec.Instance.FileSystems) > 0 {
@@ -1255,6 +1619,33 @@ func incrementContainerResource(container *corev1.Container, resourceName string
 	container.Resources.Limits[corev1.ResourceName(resourceName)] = limit
 }
 
+// addContainerResource adds quantity to both the request and the limit, if any, of the resource. Resource lists of
+// the container may be shared with the VM spec, so they are copied instead of modified in place.
+func addContainerResource(container *corev1.Container, resourceName corev1.ResourceName, quantity resource.Quantity) {
+	limit, hasLimit := container.Resources.Limits[resourceName]
+	request, hasRequest := container.Resources.Requests[resourceName]
+	if !hasRequest && hasLimit {
+		// the request defaults to the limit
+		request = limit
+	}
+	limit, request = limit.DeepCopy(), request.DeepCopy()
+
+	requests := container.Resources.Requests.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	request.Add(quantity)
+	requests[resourceName] = request
+	container.Resources.Requests = requests
+
+	if hasLimit {
+		limits := container.Resources.Limits.DeepCopy()
+		limit.Add(quantity)
+		limits[resourceName] = limit
+		container.Resources.Limits = limits
+	}
+}
+
 func (r *VMReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, "vmUID", func(obj client.Object) []string {
 		pod := obj.(*corev1.Pod)
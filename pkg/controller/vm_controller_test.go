@@ -2,11 +2,14 @@ package controller
 
 import (
 	"context"
+	"testing"
 
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -322,3 +325,153 @@ func bindPod(ctx context.Context, podKey types.NamespacedName, nodeName string)
 		Do(ctx).
 		Error()
 }
+
+func TestAddContainerResource(t *testing.T) {
+	tests := []struct {
+		resources         corev1.ResourceRequirements
+		expectedResources corev1.ResourceRequirements
+	}{{
+		resources: corev1.ResourceRequirements{},
+		expectedResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+			},
+		},
+	}, {
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+				"devices.virtink.io/kvm":        resource.MustParse("1"),
+			},
+			Limits: corev1.ResourceList{
+				"devices.virtink.io/kvm": resource.MustParse("1"),
+			},
+		},
+		expectedResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("11Gi"),
+				"devices.virtink.io/kvm":        resource.MustParse("1"),
+			},
+			Limits: corev1.ResourceList{
+				"devices.virtink.io/kvm": resource.MustParse("1"),
+			},
+		},
+	}, {
+		resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("2Gi"),
+			},
+		},
+		expectedResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("12Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: resource.MustParse("12Gi"),
+			},
+		},
+	}}
+
+	for i, tc := range tests {
+		resources := tc.resources.DeepCopy()
+		container := corev1.Container{
+			Resources: *resources,
+		}
+		addContainerResource(&container, corev1.ResourceEphemeralStorage, resource.MustParse("10Gi"))
+		assert.Equal(t, tc.resources, *resources, "case %d", i)
+		assert.Equal(t, len(tc.expectedResources.Requests), len(container.Resources.Requests), "case %d", i)
+		for name, quantity := range tc.expectedResources.Requests {
+			assert.True(t, quantity.Equal(container.Resources.Requests[name]), "case %d: request of %s", i, name)
+		}
+		assert.Equal(t, len(tc.expectedResources.Limits), len(container.Resources.Limits), "case %d", i)
+		for name, quantity := range tc.expectedResources.Limits {
+			assert.True(t, quantity.Equal(container.Resources.Limits[name]), "case %d: limit of %s", i, name)
+		}
+	}
+}
+
+func TestBuildVMPodWithHostDisk(t *testing.T) {
+	vm := &virtv1alpha1.VirtualMachine{
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "zone",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"zone-1"},
+							}},
+						}, {
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "zone",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"zone-2"},
+							}},
+						}},
+					},
+				},
+			},
+			Instance: virtv1alpha1.Instance{
+				Disks: []virtv1alpha1.Disk{{
+					Name: "ubuntu",
+				}},
+			},
+			Volumes: []virtv1alpha1.Volume{{
+				Name: "ubuntu",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path:     "/var/lib/images/ubuntu.img",
+						Type:     virtv1alpha1.HostDiskExists,
+						NodeName: "node-1",
+					},
+				},
+			}},
+		},
+	}
+
+	r := &VMReconciler{}
+	vmPod, err := r.buildVMPod(context.Background(), vm)
+	assert.NoError(t, err)
+	terms := vmPod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	assert.Len(t, terms, 2)
+	for _, term := range terms {
+		assert.Contains(t, term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      "kubernetes.io/hostname",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"node-1"},
+		})
+	}
+	// the affinity of the VM is left intact
+	assert.Len(t, vm.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions, 1)
+
+	condition, err := r.calculateMigratableCondition(context.Background(), vm)
+	assert.NoError(t, err)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, "VolumeNotMigratable", condition.Reason)
+}
+
+func TestBuildVMPodWithRustHypervisorFirmware(t *testing.T) {
+	vm := &virtv1alpha1.VirtualMachine{
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			NodeSelector: map[string]string{
+				"zone": "zone-1",
+			},
+			Instance: virtv1alpha1.Instance{
+				Firmware: &virtv1alpha1.Firmware{
+					Type: virtv1alpha1.FirmwareRustHypervisorFirmware,
+				},
+			},
+		},
+	}
+
+	r := &VMReconciler{}
+	vmPod, err := r.buildVMPod(context.Background(), vm)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"zone":               "zone-1",
+		"kubernetes.io/arch": "amd64",
+	}, vmPod.Spec.NodeSelector)
+	// the node selector of the VM is left intact
+	assert.Len(t, vm.Spec.NodeSelector, 1)
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"path/filepath"
 	"reflect"
+	"strings"
 
+	"github.com/google/uuid"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -81,6 +84,11 @@ func MutateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv
 	if vm.Spec.Instance.CPU.CoresPerSocket == 0 {
 		vm.Spec.Instance.CPU.CoresPerSocket = 1
 	}
+	// The default is applied only on creation, since VMs created before threadsPerCore was added have it unset and
+	// their specs may not be updated.
+	if oldVM == nil && vm.Spec.Instance.CPU.ThreadsPerCore == 0 {
+		vm.Spec.Instance.CPU.ThreadsPerCore = 1
+	}
 
 	if vm.Spec.Instance.Memory.Size.IsZero() {
 		if !vm.Spec.Resources.Requests.Memory().IsZero() {
@@ -91,6 +99,10 @@ func MutateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv
 	}
 
 	if vm.Spec.Instance.CPU.DedicatedCPUPlacement {
+		numCPUs := vm.Spec.Instance.CPU.Sockets * vm.Spec.Instance.CPU.CoresPerSocket * vm.Spec.Instance.CPU.GetThreadsPerCore()
+		if vm.Spec.Instance.CPU.IsolateEmulatorThread {
+			numCPUs++
+		}
 		memSize := resource.MustParse(memoryOverhead)
 		if !vm.Spec.Instance.Memory.Size.IsZero() {
 			if vm.Spec.Instance.Memory.Hugepages == nil {
@@ -98,7 +110,7 @@ func MutateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv
 			}
 		}
 		rsList := map[corev1.ResourceName]resource.Quantity{
-			corev1.ResourceCPU:    *resource.NewQuantity(int64(vm.Spec.Instance.CPU.CoresPerSocket*vm.Spec.Instance.CPU.Sockets), resource.DecimalSI),
+			corev1.ResourceCPU:    *resource.NewQuantity(int64(numCPUs), resource.DecimalSI),
 			corev1.ResourceMemory: memSize,
 		}
 
@@ -126,6 +138,9 @@ func MutateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv
 	}
 
 	if vm.Spec.Instance.Memory.Hugepages != nil {
+		if pageSize, err := resource.ParseQuantity(vm.Spec.Instance.Memory.Hugepages.PageSize); err == nil {
+			vm.Spec.Instance.Memory.Hugepages.PageSize = pageSize.String()
+		}
 		hugepagesSize := fmt.Sprintf("hugepages-%s", vm.Spec.Instance.Memory.Hugepages.PageSize)
 
 		if vm.Spec.Resources.Limits == nil {
@@ -187,6 +202,12 @@ func MutateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv
 // +kubebuilder:webhook:path=/validate-v1alpha1-virtualmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=virt.virtink.smartx.com,resources=virtualmachines,verbs=create;update,versions=v1alpha1,name=validate.virtualmachine.v1alpha1.virt.virtink.smartx.com,admissionReviewVersions={v1,v1beta1}
 
 type VMValidator struct {
+	// HostDiskPathPrefixes are the node directories under which hostDisk volumes are allowed
+	HostDiskPathPrefixes []string
+	// VhostUserSocketPathPrefixes are the node directories under which vhost-user sockets of vhostUser volumes are
+	// allowed
+	VhostUserSocketPathPrefixes []string
+
 	decoder admission.Decoder
 }
 
@@ -211,6 +232,9 @@ func (h *VMValidator) Handle(ctx context.Context, req admission.Request) admissi
 	switch req.Operation {
 	case admissionv1.Create:
 		errs = ValidateVM(ctx, &vm, nil)
+		// hostDisk and vhostUser volumes are not hotpluggable, so they are only added on creation
+		errs = append(errs, ValidateHostDiskPaths(ctx, &vm.Spec, h.HostDiskPathPrefixes, field.NewPath("spec"))...)
+		errs = append(errs, ValidateVhostUserSocketPaths(ctx, &vm.Spec, h.VhostUserSocketPathPrefixes, field.NewPath("spec"))...)
 	case admissionv1.Update:
 		var oldVM virtv1alpha1.VirtualMachine
 		if err := h.decoder.DecodeRaw(req.OldObject, &oldVM); err != nil {
@@ -230,6 +254,7 @@ func (h *VMValidator) Handle(ctx context.Context, req admission.Request) admissi
 func ValidateVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldVM *virtv1alpha1.VirtualMachine) field.ErrorList {
 	var errs field.ErrorList
 	errs = append(errs, ValidateVMSpec(ctx, &vm.Spec, field.NewPath("spec"))...)
+	errs = append(errs, ValidateBandwidthAnnotations(ctx, vm, field.NewPath("metadata", "annotations"))...)
 	if oldVM != nil {
 		errs = append(errs, ValidateVMUpdate(ctx, vm, oldVM)...)
 	}
@@ -243,11 +268,22 @@ func ValidateVMSpec(ctx context.Context, spec *virtv1alpha1.VirtualMachineSpec,
 		return errs
 	}
 
+	if spec.Instance.Firmware != nil && spec.Instance.Firmware.Type == virtv1alpha1.FirmwareRustHypervisorFirmware {
+		if arch, ok := spec.NodeSelector[corev1.LabelArchStable]; ok && arch != "amd64" {
+			errs = append(errs, field.Forbidden(fieldPath.Child("instance", "firmware", "type"), fmt.Sprintf("RustHypervisorFirmware is not supported on %s", arch)))
+		}
+	}
+
 	if spec.Instance.CPU.DedicatedCPUPlacement {
 		cpuRequestField := fieldPath.Child("resources.requests").Child(string(corev1.ResourceCPU))
+		numVCPUs := int64(spec.Instance.CPU.Sockets * spec.Instance.CPU.CoresPerSocket * spec.Instance.CPU.GetThreadsPerCore())
 		if spec.Resources.Requests.Cpu().IsZero() {
 			errs = append(errs, field.Required(cpuRequestField, ""))
-		} else if spec.Resources.Requests.Cpu().Value() != int64(spec.Instance.CPU.Sockets*spec.Instance.CPU.CoresPerSocket) {
+		} else if spec.Instance.CPU.IsolateEmulatorThread {
+			if spec.Resources.Requests.Cpu().Value() != numVCPUs+1 {
+				errs = append(errs, field.Invalid(cpuRequestField, spec.Resources.Requests.Cpu().String(), "must equal to number of vCPUs plus 1 for emulator thread"))
+			}
+		} else if spec.Resources.Requests.Cpu().Value() != numVCPUs {
 			errs = append(errs, field.Invalid(cpuRequestField, spec.Resources.Requests.Cpu().String(), "must equal to number of vCPUs"))
 		}
 
@@ -313,6 +349,62 @@ func ValidateVMSpec(ctx context.Context, spec *virtv1alpha1.VirtualMachineSpec,
 		errs = append(errs, ValidateVolume(ctx, &volume, fieldPath)...)
 	}
 
+	for i, pmem := range spec.Instance.PmemDevices {
+		for _, volume := range spec.Volumes {
+			if volume.Name == pmem.Name && volume.IsHotpluggable() {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "pmemDevices").Index(i).Child("name"), "may not use hotpluggable volume as pmem device"))
+			}
+			if volume.Name == pmem.Name && volume.VhostUser != nil {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "pmemDevices").Index(i).Child("name"), "may not use vhostUser volume as pmem device"))
+			}
+			if volume.Name == pmem.Name && (volume.ConfigMap != nil || volume.Secret != nil || volume.ServiceAccount != nil || volume.DownwardAPI != nil) {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "pmemDevices").Index(i).Child("name"), "may not use configMap, secret, serviceAccount or downwardAPI volume as pmem device"))
+			}
+		}
+	}
+
+	hasServiceAccountVolume := false
+	for i, volume := range spec.Volumes {
+		if volume.ServiceAccount == nil {
+			continue
+		}
+		if hasServiceAccountVolume {
+			errs = append(errs, field.Forbidden(fieldPath.Child("volumes").Index(i).Child("serviceAccount"), "may not specify more than 1 serviceAccount volume"))
+		}
+		hasServiceAccountVolume = true
+	}
+
+	var hostDiskNodeName string
+	for i, volume := range spec.Volumes {
+		if volume.HostDisk == nil || volume.HostDisk.NodeName == "" {
+			continue
+		}
+		if hostDiskNodeName != "" && volume.HostDisk.NodeName != hostDiskNodeName {
+			errs = append(errs, field.Forbidden(fieldPath.Child("volumes").Index(i).Child("hostDisk", "nodeName"), "may not use host disks on different nodes"))
+		}
+		hostDiskNodeName = volume.HostDisk.NodeName
+	}
+
+	for i, fs := range spec.Instance.FileSystems {
+		for _, volume := range spec.Volumes {
+			if volume.Name == fs.Name && volume.VhostUser != nil {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "fileSystems").Index(i).Child("name"), "may not use vhostUser volume as file system"))
+			}
+		}
+	}
+
+	for i, disk := range spec.Instance.Disks {
+		for _, volume := range spec.Volumes {
+			if volume.Name == disk.Name && volume.VhostUser != nil && (disk.IOLimits != nil || disk.IOLimitGroup != "") {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "disks").Index(i), "may not limit I/O of vhostUser disk"))
+			}
+			// the token would be frozen in the ISO image and expire
+			if volume.Name == disk.Name && volume.ServiceAccount != nil {
+				errs = append(errs, field.Forbidden(fieldPath.Child("instance", "disks").Index(i).Child("name"), "may not use serviceAccount volume as disk"))
+			}
+		}
+	}
+
 	networkNames := map[string]struct{}{}
 	for i, network := range spec.Networks {
 		fieldPath := fieldPath.Child("networks").Index(i)
@@ -340,13 +432,59 @@ func ValidateInstance(ctx context.Context, instance *virtv1alpha1.Instance, fiel
 		errs = append(errs, ValidateKernel(ctx, instance.Kernel, fieldPath.Child("kernel"))...)
 	}
 
+	if instance.Firmware != nil {
+		if instance.Kernel != nil {
+			errs = append(errs, field.Forbidden(fieldPath.Child("firmware"), "may not specify both kernel and firmware"))
+		}
+		errs = append(errs, ValidateFirmware(ctx, instance.Firmware, fieldPath.Child("firmware"))...)
+	}
+
+	// rust-hypervisor-firmware, which is also the default firmware on x86-64, doesn't support TPM
+	if instance.TPM != nil && instance.Kernel == nil && (instance.Firmware == nil || instance.Firmware.Type == virtv1alpha1.FirmwareRustHypervisorFirmware) {
+		errs = append(errs, field.Forbidden(fieldPath.Child("tpm"), "may not use TPM without kernel or firmware other than RustHypervisorFirmware"))
+	}
+
+	if instance.CPU.Realtime != nil && instance.Memory.Hugepages == nil {
+		errs = append(errs, field.Forbidden(fieldPath.Child("cpu", "realtime"), "may not enable realtime without hugepages"))
+	}
+
+	if instance.Platform != nil {
+		errs = append(errs, ValidatePlatform(ctx, instance.Platform, fieldPath.Child("platform"))...)
+	}
+
+	if instance.NUMA != nil {
+		errs = append(errs, ValidateNUMA(ctx, instance.NUMA, &instance.CPU, &instance.Memory, fieldPath.Child("numa"))...)
+	}
+
+	diskIOLimitGroupNames := map[string]struct{}{}
+	for i, group := range instance.DiskIOLimitGroups {
+		fieldPath := fieldPath.Child("diskIOLimitGroups").Index(i)
+		if _, ok := diskIOLimitGroupNames[group.Name]; ok {
+			errs = append(errs, field.Duplicate(fieldPath.Child("name"), group.Name))
+		}
+		diskIOLimitGroupNames[group.Name] = struct{}{}
+		errs = append(errs, ValidateDiskIOLimitGroup(ctx, &group, fieldPath)...)
+	}
+
 	diskNames := map[string]struct{}{}
+	diskSerials := map[string]struct{}{}
 	for i, disk := range instance.Disks {
 		fieldPath := fieldPath.Child("disks").Index(i)
 		if _, ok := diskNames[disk.Name]; ok {
 			errs = append(errs, field.Duplicate(fieldPath.Child("name"), disk.Name))
 		}
 		diskNames[disk.Name] = struct{}{}
+		// the serial defaults to the truncated disk name, which may collide with other serials
+		serial := disk.GetSerial()
+		if _, ok := diskSerials[serial]; ok {
+			errs = append(errs, field.Duplicate(fieldPath.Child("serial"), serial))
+		}
+		diskSerials[serial] = struct{}{}
+		if disk.IOLimitGroup != "" {
+			if _, ok := diskIOLimitGroupNames[disk.IOLimitGroup]; !ok {
+				errs = append(errs, field.NotFound(fieldPath.Child("ioLimitGroup"), disk.IOLimitGroup))
+			}
+		}
 		errs = append(errs, ValidateDisk(ctx, &disk, fieldPath)...)
 	}
 
@@ -359,6 +497,15 @@ func ValidateInstance(ctx context.Context, instance *virtv1alpha1.Instance, fiel
 		errs = append(errs, ValidateFileSystem(ctx, &fs, fieldPath)...)
 	}
 
+	for i, pmem := range instance.PmemDevices {
+		fieldPath := fieldPath.Child("pmemDevices").Index(i)
+		if _, ok := diskNames[pmem.Name]; ok {
+			errs = append(errs, field.Duplicate(fieldPath.Child("name"), pmem.Name))
+		}
+		diskNames[pmem.Name] = struct{}{}
+		errs = append(errs, ValidatePmemDevice(ctx, &pmem, fieldPath)...)
+	}
+
 	ifaceNames := map[string]struct{}{}
 	for i, iface := range instance.Interfaces {
 		fieldPath := fieldPath.Child("interfaces").Index(i)
@@ -390,6 +537,28 @@ func ValidateCPU(ctx context.Context, cpu *virtv1alpha1.CPU, fieldPath *field.Pa
 	if cpu.CoresPerSocket <= 0 {
 		errs = append(errs, field.Required(fieldPath.Child("coresPerSocket"), ""))
 	}
+	if cpu.IsolateEmulatorThread && !cpu.DedicatedCPUPlacement {
+		errs = append(errs, field.Forbidden(fieldPath.Child("isolateEmulatorThread"), "may not isolate emulator thread without dedicated CPU placement"))
+	}
+	if cpu.Realtime != nil {
+		if !cpu.DedicatedCPUPlacement {
+			errs = append(errs, field.Forbidden(fieldPath.Child("realtime"), "may not enable realtime without dedicated CPU placement"))
+		}
+		if cpu.Realtime.Priority < 1 || cpu.Realtime.Priority > 99 {
+			errs = append(errs, field.Invalid(fieldPath.Child("realtime", "priority"), cpu.Realtime.Priority, "must be in the range of [1, 99]"))
+		}
+	}
+	if cpu.MaxPhysBits < 0 || cpu.MaxPhysBits > 52 {
+		errs = append(errs, field.Invalid(fieldPath.Child("maxPhysBits"), cpu.MaxPhysBits, "must be in the range of [0, 52], where 0 means unset"))
+	}
+
+	features := map[virtv1alpha1.CPUFeature]struct{}{}
+	for i, feature := range cpu.Features {
+		if _, ok := features[feature]; ok {
+			errs = append(errs, field.Duplicate(fieldPath.Child("features").Index(i), feature))
+		}
+		features[feature] = struct{}{}
+	}
 	return errs
 }
 
@@ -405,13 +574,90 @@ func ValidateMemory(ctx context.Context, memory *virtv1alpha1.Memory, fieldPath
 		errs = append(errs, field.Invalid(fieldPath.Child("size"), memSize, "must be greater than 0"))
 	}
 	if memory.Hugepages != nil {
-		q := resource.MustParse(memory.Hugepages.PageSize)
-		hugepagesSize := q.Value()
-		if memSize%hugepagesSize != 0 {
+		pageSizeField := fieldPath.Child("hugepages", "pageSize")
+		if q, err := resource.ParseQuantity(memory.Hugepages.PageSize); err != nil {
+			errs = append(errs, field.Invalid(pageSizeField, memory.Hugepages.PageSize, err.Error()))
+		} else if hugepagesSize := q.Value(); hugepagesSize < 64*1024 || hugepagesSize&(hugepagesSize-1) != 0 {
+			errs = append(errs, field.Invalid(pageSizeField, memory.Hugepages.PageSize, "must be a power of 2 no less than 64Ki"))
+		} else if memSize%hugepagesSize != 0 {
 			errs = append(errs, field.Invalid(fieldPath.Child("size"), memSize, fmt.Sprintf("%d is not positive integer multiple of %s", memSize, memory.Hugepages.PageSize)))
 		}
+
+		if memory.Mergeable {
+			errs = append(errs, field.Forbidden(fieldPath.Child("mergeable"), "may not merge hugepages"))
+		}
+	}
+
+	return errs
+}
+
+func ValidateNUMA(ctx context.Context, numa *virtv1alpha1.NUMA, cpu *virtv1alpha1.CPU, memory *virtv1alpha1.Memory, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if numa == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if len(numa.Nodes) == 0 {
+		errs = append(errs, field.Required(fieldPath.Child("nodes"), ""))
+		return errs
+	}
+
+	numVCPUs := int(cpu.Sockets * cpu.CoresPerSocket * cpu.GetThreadsPerCore())
+	vcpus := map[int]struct{}{}
+	var memSize int64
+	for i, node := range numa.Nodes {
+		fieldPath := fieldPath.Child("nodes").Index(i)
+		if len(node.CPUs) == 0 {
+			errs = append(errs, field.Required(fieldPath.Child("cpus"), ""))
+		}
+		for j, vcpu := range node.CPUs {
+			if vcpu < 0 || vcpu >= numVCPUs {
+				errs = append(errs, field.Invalid(fieldPath.Child("cpus").Index(j), vcpu, fmt.Sprintf("must be in the range of [0, %d)", numVCPUs)))
+			}
+			if _, ok := vcpus[vcpu]; ok {
+				errs = append(errs, field.Duplicate(fieldPath.Child("cpus").Index(j), vcpu))
+			}
+			vcpus[vcpu] = struct{}{}
+		}
+
+		nodeMemSize := node.Memory.Value()
+		if nodeMemSize <= 0 {
+			errs = append(errs, field.Invalid(fieldPath.Child("memory"), nodeMemSize, "must be greater than 0"))
+		}
+		if memory.Hugepages != nil {
+			if q, err := resource.ParseQuantity(memory.Hugepages.PageSize); err == nil && q.Value() > 0 && nodeMemSize%q.Value() != 0 {
+				errs = append(errs, field.Invalid(fieldPath.Child("memory"), nodeMemSize, fmt.Sprintf("%d is not positive integer multiple of %s", nodeMemSize, memory.Hugepages.PageSize)))
+			}
+		}
+		memSize += nodeMemSize
+	}
+
+	if len(vcpus) != numVCPUs {
+		errs = append(errs, field.Invalid(fieldPath.Child("nodes"), len(vcpus), fmt.Sprintf("must cover all %d vCPUs", numVCPUs)))
 	}
+	if memSize != memory.Size.Value() {
+		errs = append(errs, field.Invalid(fieldPath.Child("nodes"), memSize, "total memory of nodes must equal to memory size"))
+	}
+	return errs
+}
 
+func ValidateFirmware(ctx context.Context, firmware *virtv1alpha1.Firmware, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if firmware == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if firmware.Type == "" && firmware.Image == "" {
+		errs = append(errs, field.Required(fieldPath, "at least 1 of type and image is required"))
+	}
+	if firmware.Type != "" && firmware.Image != "" {
+		errs = append(errs, field.Forbidden(fieldPath.Child("image"), "may not specify both type and image"))
+	}
+	if firmware.ImagePullPolicy != "" && firmware.Image == "" {
+		errs = append(errs, field.Forbidden(fieldPath.Child("imagePullPolicy"), "may not specify imagePullPolicy without image"))
+	}
 	return errs
 }
 
@@ -441,6 +687,68 @@ func ValidateDisk(ctx context.Context, disk *virtv1alpha1.Disk, fieldPath *field
 	if disk.Name == "" {
 		errs = append(errs, field.Required(fieldPath.Child("name"), ""))
 	}
+	if disk.IOLimits != nil {
+		if disk.IOLimitGroup != "" {
+			errs = append(errs, field.Forbidden(fieldPath.Child("ioLimits"), "may not specify both ioLimits and ioLimitGroup"))
+		}
+		errs = append(errs, ValidateDiskIOLimits(ctx, disk.IOLimits, fieldPath.Child("ioLimits"))...)
+	}
+	if disk.NumQueues < 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("numQueues"), disk.NumQueues, "must be greater than 0"))
+	}
+	errs = append(errs, ValidateQueueSize(disk.QueueSize, fieldPath.Child("queueSize"))...)
+	if len(disk.Serial) > virtv1alpha1.MaxDiskSerialLength {
+		errs = append(errs, field.TooLong(fieldPath.Child("serial"), disk.Serial, virtv1alpha1.MaxDiskSerialLength))
+	}
+	return errs
+}
+
+func ValidateDiskIOLimitGroup(ctx context.Context, group *virtv1alpha1.DiskIOLimitGroup, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if group == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if group.Name == "" {
+		errs = append(errs, field.Required(fieldPath.Child("name"), ""))
+	}
+	errs = append(errs, ValidateDiskIOLimits(ctx, &group.DiskIOLimits, fieldPath)...)
+	return errs
+}
+
+func ValidateDiskIOLimits(ctx context.Context, limits *virtv1alpha1.DiskIOLimits, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if limits == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if limits.Bandwidth == nil && limits.IOPS == 0 {
+		errs = append(errs, field.Required(fieldPath, "at least 1 of bandwidth and iops is required"))
+	}
+	if limits.Bandwidth != nil && limits.Bandwidth.Value() <= 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("bandwidth"), limits.Bandwidth.String(), "must be greater than 0"))
+	}
+	if limits.BandwidthBurst != nil {
+		if limits.Bandwidth == nil {
+			errs = append(errs, field.Forbidden(fieldPath.Child("bandwidthBurst"), "may not specify bandwidthBurst without bandwidth"))
+		}
+		if limits.BandwidthBurst.Value() < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Child("bandwidthBurst"), limits.BandwidthBurst.String(), "must be greater than or equal to 0"))
+		}
+	}
+	if limits.IOPS < 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("iops"), limits.IOPS, "must be greater than 0"))
+	}
+	if limits.IOPSBurst != 0 {
+		if limits.IOPS == 0 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("iopsBurst"), "may not specify iopsBurst without iops"))
+		}
+		if limits.IOPSBurst < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Child("iopsBurst"), limits.IOPSBurst, "must be greater than or equal to 0"))
+		}
+	}
 	return errs
 }
 
@@ -457,6 +765,19 @@ func ValidateFileSystem(ctx context.Context, fs *virtv1alpha1.FileSystem, fieldP
 	return errs
 }
 
+func ValidatePmemDevice(ctx context.Context, pmem *virtv1alpha1.PmemDevice, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if pmem == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if pmem.Name == "" {
+		errs = append(errs, field.Required(fieldPath.Child("name"), ""))
+	}
+	return errs
+}
+
 func ValidateInterface(ctx context.Context, iface *virtv1alpha1.Interface, fieldPath *field.Path) field.ErrorList {
 	var errs field.ErrorList
 	if iface == nil {
@@ -469,6 +790,141 @@ func ValidateInterface(ctx context.Context, iface *virtv1alpha1.Interface, field
 	}
 	errs = append(errs, ValidateMAC(iface.MAC, fieldPath.Child("mac"))...)
 	errs = append(errs, ValidateInterfaceBindingMethod(ctx, &iface.InterfaceBindingMethod, fieldPath)...)
+	if iface.RateLimit != nil {
+		if iface.Bridge == nil && iface.Masquerade == nil {
+			errs = append(errs, field.Forbidden(fieldPath.Child("rateLimit"), "may only specify rateLimit for bridge or masquerade interface"))
+		}
+		errs = append(errs, ValidateInterfaceRateLimit(ctx, iface.RateLimit, fieldPath.Child("rateLimit"))...)
+	}
+	if iface.NumQueues != 0 || iface.QueueSize != 0 {
+		if iface.Bridge == nil && iface.Masquerade == nil && iface.VhostUser == nil {
+			errs = append(errs, field.Forbidden(fieldPath, "may only specify numQueues and queueSize for bridge, masquerade or vhostUser interface"))
+		}
+	}
+	if iface.NumQueues < 0 || iface.NumQueues%2 != 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("numQueues"), iface.NumQueues, "must be a positive even number"))
+	}
+	errs = append(errs, ValidateQueueSize(iface.QueueSize, fieldPath.Child("queueSize"))...)
+	return errs
+}
+
+func ValidateQueueSize(queueSize int, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if queueSize == 0 {
+		return errs
+	}
+
+	if queueSize < 0 || queueSize > 32768 || queueSize&(queueSize-1) != 0 {
+		errs = append(errs, field.Invalid(fieldPath, queueSize, "must be a power of 2 no greater than 32768"))
+	}
+	return errs
+}
+
+func ValidatePlatform(ctx context.Context, platform *virtv1alpha1.Platform, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if platform == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if platform.UUID != "" {
+		if _, err := uuid.Parse(platform.UUID); err != nil {
+			errs = append(errs, field.Invalid(fieldPath.Child("uuid"), platform.UUID, err.Error()))
+		}
+	}
+	for i, oemString := range platform.OEMStrings {
+		if oemString == "" {
+			errs = append(errs, field.Required(fieldPath.Child("oemStrings").Index(i), ""))
+		}
+	}
+	return errs
+}
+
+func ValidateInterfaceRateLimit(ctx context.Context, rateLimit *virtv1alpha1.InterfaceRateLimit, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if rateLimit == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if rateLimit.Bandwidth == nil && rateLimit.Packets == 0 {
+		errs = append(errs, field.Required(fieldPath, "at least 1 of bandwidth and packets is required"))
+	}
+	if rateLimit.Bandwidth != nil && rateLimit.Bandwidth.Value() <= 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("bandwidth"), rateLimit.Bandwidth.String(), "must be greater than 0"))
+	}
+	if rateLimit.BandwidthBurst != nil {
+		if rateLimit.Bandwidth == nil {
+			errs = append(errs, field.Forbidden(fieldPath.Child("bandwidthBurst"), "may not specify bandwidthBurst without bandwidth"))
+		}
+		if rateLimit.BandwidthBurst.Value() < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Child("bandwidthBurst"), rateLimit.BandwidthBurst.String(), "must be greater than or equal to 0"))
+		}
+	}
+	if rateLimit.Packets < 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("packets"), rateLimit.Packets, "must be greater than 0"))
+	}
+	if rateLimit.PacketsBurst != 0 {
+		if rateLimit.Packets == 0 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("packetsBurst"), "may not specify packetsBurst without packets"))
+		}
+		if rateLimit.PacketsBurst < 0 {
+			errs = append(errs, field.Invalid(fieldPath.Child("packetsBurst"), rateLimit.PacketsBurst, "must be greater than or equal to 0"))
+		}
+	}
+	return errs
+}
+
+// ValidateBandwidthAnnotations validates the bandwidth annotations of a VM, which are honored for bridge and masquerade
+// interfaces connected to the Pod network without rateLimit. Cloud Hypervisor applies the same limit to both
+// directions, so the ingress and egress bandwidth must be the same.
+func ValidateBandwidthAnnotations(ctx context.Context, vm *virtv1alpha1.VirtualMachine, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	honored := false
+	for _, iface := range vm.Spec.Instance.Interfaces {
+		if iface.RateLimit != nil || (iface.Bridge == nil && iface.Masquerade == nil) {
+			continue
+		}
+		for _, network := range vm.Spec.Networks {
+			if network.Name == iface.Name && network.Pod != nil {
+				honored = true
+			}
+		}
+	}
+	if !honored {
+		return errs
+	}
+
+	const ingressAnnotation, egressAnnotation = "kubernetes.io/ingress-bandwidth", "kubernetes.io/egress-bandwidth"
+	ingress, hasIngress := vm.Annotations[ingressAnnotation]
+	egress, hasEgress := vm.Annotations[egressAnnotation]
+	switch {
+	case !hasIngress && !hasEgress:
+		return errs
+	case !hasIngress:
+		errs = append(errs, field.Required(fieldPath.Key(ingressAnnotation), "must specify both ingress and egress bandwidth, since they are applied as the same limit"))
+		return errs
+	case !hasEgress:
+		errs = append(errs, field.Required(fieldPath.Key(egressAnnotation), "must specify both ingress and egress bandwidth, since they are applied as the same limit"))
+		return errs
+	}
+
+	ingressBandwidth, err := resource.ParseQuantity(ingress)
+	if err != nil {
+		errs = append(errs, field.Invalid(fieldPath.Key(ingressAnnotation), ingress, err.Error()))
+		return errs
+	}
+	if ingressBandwidth.Value() < 8 {
+		errs = append(errs, field.Invalid(fieldPath.Key(ingressAnnotation), ingress, "must be at least 8"))
+	}
+	egressBandwidth, err := resource.ParseQuantity(egress)
+	if err != nil {
+		errs = append(errs, field.Invalid(fieldPath.Key(egressAnnotation), egress, err.Error()))
+		return errs
+	}
+	if !egressBandwidth.Equal(ingressBandwidth) {
+		errs = append(errs, field.Invalid(fieldPath.Key(egressAnnotation), egress, "must equal to ingress bandwidth, since they are applied as the same limit"))
+	}
 	return errs
 }
 
@@ -613,12 +1069,98 @@ func ValidateVolumeSource(ctx context.Context, source *virtv1alpha1.VolumeSource
 			errs = append(errs, ValidateDataVolumeSource(ctx, source.DataVolume, fieldPath.Child("dataVolume"))...)
 		}
 	}
+	if source.VhostUser != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("vhostUser"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateVhostUserVolumeSource(ctx, source.VhostUser, fieldPath.Child("vhostUser"))...)
+		}
+	}
+	if source.EmptyDisk != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("emptyDisk"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateEmptyDiskVolumeSource(ctx, source.EmptyDisk, fieldPath.Child("emptyDisk"))...)
+		}
+	}
+	if source.HostDisk != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("hostDisk"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateHostDiskVolumeSource(ctx, source.HostDisk, fieldPath.Child("hostDisk"))...)
+		}
+	}
+	if source.ConfigMap != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("configMap"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateConfigMapVolumeSource(ctx, source.ConfigMap, fieldPath.Child("configMap"))...)
+		}
+	}
+	if source.Secret != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("secret"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateSecretVolumeSource(ctx, source.Secret, fieldPath.Child("secret"))...)
+		}
+	}
+	if source.ServiceAccount != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("serviceAccount"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateServiceAccountVolumeSource(ctx, source.ServiceAccount, fieldPath.Child("serviceAccount"))...)
+		}
+	}
+	if source.DownwardAPI != nil {
+		cnt++
+		if cnt > 1 {
+			errs = append(errs, field.Forbidden(fieldPath.Child("downwardAPI"), "may not specify more than 1 volume source"))
+		} else {
+			errs = append(errs, ValidateDownwardAPIVolumeSource(ctx, source.DownwardAPI, fieldPath.Child("downwardAPI"))...)
+		}
+	}
 	if cnt == 0 {
 		errs = append(errs, field.Required(fieldPath, "at least 1 volume source is required"))
 	}
 	return errs
 }
 
+func ValidateVhostUserVolumeSource(ctx context.Context, source *virtv1alpha1.VhostUserVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.HostPath == "" && source.ClaimName == "" {
+		errs = append(errs, field.Required(fieldPath, "at least 1 of hostPath and claimName is required"))
+	}
+	if source.HostPath != "" {
+		if source.ClaimName != "" {
+			errs = append(errs, field.Forbidden(fieldPath.Child("claimName"), "may not specify both hostPath and claimName"))
+		}
+		if !filepath.IsAbs(source.HostPath) {
+			errs = append(errs, field.Invalid(fieldPath.Child("hostPath"), source.HostPath, "must be an absolute path"))
+		}
+		if source.SocketName != "" {
+			errs = append(errs, field.Forbidden(fieldPath.Child("socketName"), "may not specify socketName with hostPath"))
+		}
+	}
+	if source.ClaimName != "" && source.SocketName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("socketName"), ""))
+	}
+	if source.SocketName != "" && (strings.Contains(source.SocketName, "/") || source.SocketName == "." || source.SocketName == "..") {
+		errs = append(errs, field.Invalid(fieldPath.Child("socketName"), source.SocketName, "must be a file name"))
+	}
+	return errs
+}
+
 func ValidateContainerDiskVolumeSource(ctx context.Context, source *virtv1alpha1.ContainerDiskVolumeSource, fieldPath *field.Path) field.ErrorList {
 	var errs field.ErrorList
 	if source == nil {
@@ -697,6 +1239,142 @@ func ValidateContainerRootfsVolumeSource(ctx context.Context, source *virtv1alph
 	return errs
 }
 
+func ValidateEmptyDiskVolumeSource(ctx context.Context, source *virtv1alpha1.EmptyDiskVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.Capacity.Value() <= 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("capacity"), source.Capacity.Value(), "must be greater than 0"))
+	}
+	return errs
+}
+
+func ValidateHostDiskVolumeSource(ctx context.Context, source *virtv1alpha1.HostDiskVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.Path == "" {
+		errs = append(errs, field.Required(fieldPath.Child("path"), ""))
+	} else if !filepath.IsAbs(source.Path) {
+		errs = append(errs, field.Invalid(fieldPath.Child("path"), source.Path, "must be an absolute path"))
+	}
+	if source.NodeName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("nodeName"), ""))
+	}
+	if source.Type == virtv1alpha1.HostDiskExistsOrCreate {
+		if source.Capacity == nil {
+			errs = append(errs, field.Required(fieldPath.Child("capacity"), ""))
+		}
+	} else if source.Capacity != nil {
+		errs = append(errs, field.Forbidden(fieldPath.Child("capacity"), "may only specify capacity for DiskOrCreate host disk"))
+	}
+	if source.Capacity != nil && source.Capacity.Value() <= 0 {
+		errs = append(errs, field.Invalid(fieldPath.Child("capacity"), source.Capacity.Value(), "must be greater than 0"))
+	}
+	return errs
+}
+
+func ValidateConfigMapVolumeSource(ctx context.Context, source *corev1.ConfigMapVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.Name == "" {
+		errs = append(errs, field.Required(fieldPath.Child("name"), ""))
+	}
+	return errs
+}
+
+func ValidateSecretVolumeSource(ctx context.Context, source *corev1.SecretVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.SecretName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("secretName"), ""))
+	}
+	return errs
+}
+
+func ValidateServiceAccountVolumeSource(ctx context.Context, source *virtv1alpha1.ServiceAccountVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if source.ServiceAccountName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("serviceAccountName"), ""))
+	}
+	return errs
+}
+
+func ValidateDownwardAPIVolumeSource(ctx context.Context, source *corev1.DownwardAPIVolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if len(source.Items) == 0 {
+		errs = append(errs, field.Required(fieldPath.Child("items"), ""))
+	}
+	return errs
+}
+
+// ValidateHostDiskPaths checks that hostDisk volumes are located under the allowed node directories. No hostDisk
+// volume is allowed if there is no allowed directory.
+func ValidateHostDiskPaths(ctx context.Context, spec *virtv1alpha1.VirtualMachineSpec, pathPrefixes []string, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, volume := range spec.Volumes {
+		if volume.HostDisk == nil || volume.HostDisk.Path == "" {
+			continue
+		}
+
+		if !isPathUnderPrefixes(volume.HostDisk.Path, pathPrefixes) {
+			errs = append(errs, field.Forbidden(fieldPath.Child("volumes").Index(i).Child("hostDisk", "path"), fmt.Sprintf("may only use host disk under %s", strings.Join(pathPrefixes, ", "))))
+		}
+	}
+	return errs
+}
+
+// ValidateVhostUserSocketPaths checks that vhost-user sockets of vhostUser volumes on the node are located under the
+// allowed node directories. No such socket is allowed if there is no allowed directory.
+func ValidateVhostUserSocketPaths(ctx context.Context, spec *virtv1alpha1.VirtualMachineSpec, pathPrefixes []string, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, volume := range spec.Volumes {
+		if volume.VhostUser == nil || volume.VhostUser.HostPath == "" {
+			continue
+		}
+
+		if !isPathUnderPrefixes(volume.VhostUser.HostPath, pathPrefixes) {
+			errs = append(errs, field.Forbidden(fieldPath.Child("volumes").Index(i).Child("vhostUser", "hostPath"), fmt.Sprintf("may only use vhost-user socket under %s", strings.Join(pathPrefixes, ", "))))
+		}
+	}
+	return errs
+}
+
+func isPathUnderPrefixes(path string, prefixes []string) bool {
+	path = filepath.Clean(path)
+	for _, prefix := range prefixes {
+		prefix = filepath.Clean(prefix)
+		if strings.HasPrefix(path, prefix+"/") || prefix == "/" {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidatePersistentVolumeClaimSource(ctx context.Context, source *virtv1alpha1.PersistentVolumeClaimVolumeSource, fieldPath *field.Path) field.ErrorList {
 	var errs field.ErrorList
 	if source == nil {
@@ -808,7 +1486,7 @@ func ValidateVMUpdate(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldV
 			}
 		}
 		if newVolume != nil {
-			if !reflect.DeepEqual(oldVolume, *newVolume) {
+			if !reflect.DeepEqual(oldVolume, *newVolume) && !isVolumeMoveSwitch(oldVM, newVolume) {
 				errs = append(errs, field.Forbidden(field.NewPath("spec").Child("volumes").Key(oldVolume.Name), "VM volume may not be updated"))
 			}
 		} else {
@@ -835,6 +1513,12 @@ func ValidateVMUpdate(ctx context.Context, vm *virtv1alpha1.VirtualMachine, oldV
 	return errs
 }
 
+func isVolumeMoveSwitch(vm *virtv1alpha1.VirtualMachine, newVolume *virtv1alpha1.Volume) bool {
+	volumeMove := vm.Status.VolumeMove
+	return volumeMove != nil && volumeMove.Phase == virtv1alpha1.VirtualMachineVolumeMoveSwitched &&
+		volumeMove.VolumeName == newVolume.Name && reflect.DeepEqual(volumeMove.TargetVolume.VolumeSource, newVolume.VolumeSource)
+}
+
 func generateMAC() (net.HardwareAddr, error) {
 	prefix := []byte{0x52, 0x54, 0x00}
 	suffix := make([]byte, 3)
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
 )
@@ -18,6 +19,7 @@ func TestValidateVM(t *testing.T) {
 				CPU: virtv1alpha1.CPU{
 					Sockets:        1,
 					CoresPerSocket: 1,
+					ThreadsPerCore: 1,
 				},
 				Memory: virtv1alpha1.Memory{
 					Size: resource.MustParse("1Gi"),
@@ -127,6 +129,40 @@ func TestValidateVM(t *testing.T) {
 			return vm
 		}(),
 		invalidFields: []string{"spec.resources.requests.cpu"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.DedicatedCPUPlacement = true
+			vm.Spec.Instance.CPU.IsolateEmulatorThread = true
+			vm.Spec.Resources = corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1280Mi"),
+				},
+				Limits: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceCPU:    resource.MustParse("1"),
+					corev1.ResourceMemory: resource.MustParse("1280Mi"),
+				},
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.resources.requests.cpu"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.IsolateEmulatorThread = true
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.cpu.isolateEmulatorThread"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.Realtime = &virtv1alpha1.CPURealtime{
+				Priority: 100,
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.cpu.realtime", "spec.instance.cpu.realtime.priority"},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -223,6 +259,34 @@ func TestValidateVM(t *testing.T) {
 			return vm
 		}(),
 		invalidFields: []string{"spec.resources.limits.hugepages-2Mi"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Memory.Hugepages = &virtv1alpha1.Hugepages{
+				PageSize: "3Mi",
+			}
+			vm.Spec.Instance.Memory.Mergeable = true
+			vm.Spec.Resources = corev1.ResourceRequirements{
+				Requests: map[corev1.ResourceName]resource.Quantity{
+					corev1.ResourceMemory: resource.MustParse(memoryOverhead),
+					"hugepages-3Mi":       resource.MustParse("1Gi"),
+				},
+				Limits: map[corev1.ResourceName]resource.Quantity{
+					"hugepages-3Mi": resource.MustParse("1Gi"),
+				},
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.memory.hugepages.pageSize", "spec.instance.memory.mergeable"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			thp := false
+			vm.Spec.Instance.Memory.TransparentHugepages = &thp
+			vm.Spec.Instance.Memory.Mergeable = true
+			vm.Spec.Instance.Memory.Prefault = true
+			return vm
+		}(),
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -237,6 +301,437 @@ func TestValidateVM(t *testing.T) {
 			return vm
 		}(),
 		invalidFields: []string{"spec.instance.cpu.coresPerSocket"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.ThreadsPerCore = 0
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type: virtv1alpha1.FirmwareOVMF,
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type: virtv1alpha1.FirmwareOVMF,
+			}
+			vm.Spec.Instance.TPM = &virtv1alpha1.TPM{}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Kernel = &virtv1alpha1.Kernel{
+				Image:   "kernel",
+				Cmdline: "console=ttyS0",
+			}
+			vm.Spec.Instance.TPM = &virtv1alpha1.TPM{}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type: virtv1alpha1.FirmwareRustHypervisorFirmware,
+			}
+			vm.Spec.Instance.TPM = &virtv1alpha1.TPM{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.tpm"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.TPM = &virtv1alpha1.TPM{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.tpm"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type: virtv1alpha1.FirmwareRustHypervisorFirmware,
+			}
+			vm.Spec.NodeSelector = map[string]string{
+				"kubernetes.io/arch": "amd64",
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type: virtv1alpha1.FirmwareRustHypervisorFirmware,
+			}
+			vm.Spec.NodeSelector = map[string]string{
+				"kubernetes.io/arch": "arm64",
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.firmware.type"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Firmware = &virtv1alpha1.Firmware{
+				Type:  virtv1alpha1.FirmwareOVMF,
+				Image: "firmware",
+			}
+			vm.Spec.Instance.Kernel = &virtv1alpha1.Kernel{
+				Image:   "kernel",
+				Cmdline: "console=ttyS0",
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.firmware", "spec.instance.firmware.image"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.ThreadsPerCore = 2
+			vm.Spec.Instance.CPU.MaxPhysBits = 46
+			vm.Spec.Instance.CPU.KVMHyperv = true
+			vm.Spec.Instance.CPU.Features = []virtv1alpha1.CPUFeature{virtv1alpha1.CPUFeatureAMX}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.MaxPhysBits = 64
+			vm.Spec.Instance.CPU.Features = []virtv1alpha1.CPUFeature{virtv1alpha1.CPUFeatureAMX, virtv1alpha1.CPUFeatureAMX}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.cpu.maxPhysBits", "spec.instance.cpu.features[1]"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{
+				UUID:         "c1f2a7b6-5d8e-4c3a-9b0f-1e2d3c4b5a69",
+				SerialNumber: "VM-0001",
+				OEMStrings:   []string{"io.systemd.credential:hostname=vm-0001"},
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{
+				UUID:       "not-a-uuid",
+				OEMStrings: []string{""},
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.platform.uuid", "spec.instance.platform.oemStrings[0]"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					EmptyDisk: &virtv1alpha1.EmptyDiskVolumeSource{
+						Capacity: resource.MustParse("10Gi"),
+					},
+				},
+			})
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					EmptyDisk: &virtv1alpha1.EmptyDiskVolumeSource{},
+				},
+			})
+			return vm
+		}(),
+		invalidFields: []string{"spec.volumes[3].emptyDisk.capacity"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			}, virtv1alpha1.Disk{
+				Name: "vol-5",
+			})
+			vm.Spec.Instance.FileSystems = append(vm.Spec.Instance.FileSystems, virtv1alpha1.FileSystem{
+				Name: "vol-6",
+			}, virtv1alpha1.FileSystem{
+				Name: "vol-7",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: "vol-4",
+						},
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-5",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: "vol-5",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-6",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ServiceAccount: &virtv1alpha1.ServiceAccountVolumeSource{
+						ServiceAccountName: "vol-6",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-7",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					DownwardAPI: &corev1.DownwardAPIVolumeSource{
+						Items: []corev1.DownwardAPIVolumeFile{{
+							Path: "labels",
+							FieldRef: &corev1.ObjectFieldSelector{
+								FieldPath: "metadata.labels",
+							},
+						}},
+					},
+				},
+			})
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			}, virtv1alpha1.Disk{
+				Name: "vol-5",
+			}, virtv1alpha1.Disk{
+				Name: "vol-6",
+			}, virtv1alpha1.Disk{
+				Name: "vol-7",
+			})
+			vm.Spec.Instance.PmemDevices = []virtv1alpha1.PmemDevice{{
+				Name: "vol-7",
+			}}
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-5",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ServiceAccount: &virtv1alpha1.ServiceAccountVolumeSource{
+						ServiceAccountName: "vol-5",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-6",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ServiceAccount: &virtv1alpha1.ServiceAccountVolumeSource{
+						ServiceAccountName: "vol-6",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-7",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: "vol-7",
+					},
+				},
+			})
+			return vm
+		}(),
+		invalidFields: []string{"spec.volumes[3].configMap.name", "spec.volumes[5].serviceAccount", "spec.instance.pmemDevices[0].name", "spec.instance.disks[3].name", "spec.instance.disks[4].name"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			capacity := resource.MustParse("10Gi")
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			}, virtv1alpha1.Disk{
+				Name: "vol-5",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path:     "/var/lib/images/ubuntu.img",
+						Type:     virtv1alpha1.HostDiskExists,
+						NodeName: "node-1",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-5",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path:     "/var/lib/images/data.img",
+						Type:     virtv1alpha1.HostDiskExistsOrCreate,
+						Capacity: &capacity,
+						NodeName: "node-1",
+					},
+				},
+			})
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			capacity := resource.MustParse("10Gi")
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			}, virtv1alpha1.Disk{
+				Name: "vol-5",
+			}, virtv1alpha1.Disk{
+				Name: "vol-6",
+			}, virtv1alpha1.Disk{
+				Name: "vol-7",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path:     "images/ubuntu.img",
+						Type:     virtv1alpha1.HostDiskExists,
+						Capacity: &capacity,
+						NodeName: "node-1",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-5",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path: "/var/lib/images/data.img",
+						Type: virtv1alpha1.HostDiskExistsOrCreate,
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-6",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path:     "/var/lib/images/ubuntu.img",
+						NodeName: "node-2",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-7",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+						Path: "/var/lib/images/debian.img",
+						Type: virtv1alpha1.HostDiskExists,
+					},
+				},
+			})
+			return vm
+		}(),
+		invalidFields: []string{"spec.volumes[3].hostDisk.path", "spec.volumes[3].hostDisk.capacity", "spec.volumes[4].hostDisk.capacity", "spec.volumes[4].hostDisk.nodeName", "spec.volumes[5].hostDisk.nodeName", "spec.volumes[6].hostDisk.nodeName"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].Cache = virtv1alpha1.DiskCacheWriteback
+			vm.Spec.Instance.Disks[0].Serial = "os-disk"
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].Serial = "a-serial-longer-than-20-bytes"
+			vm.Spec.Instance.Disks[1].Serial = "a-serial-longer-than-20-bytes"
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].serial", "spec.instance.disks[1].serial", "spec.instance.disks[1].serial"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].Serial = "vol-3"
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[1].serial"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "vol-4",
+					},
+				},
+			})
+			vm.Spec.Instance.PmemDevices = []virtv1alpha1.PmemDevice{{
+				Name:          "vol-4",
+				DiscardWrites: true,
+			}}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = vm.Spec.Instance.Disks[:1]
+			vm.Spec.Instance.PmemDevices = []virtv1alpha1.PmemDevice{{
+				Name: "vol-1",
+			}, {
+				Name: "vol-3",
+			}}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.pmemDevices[0].name", "spec.instance.pmemDevices[1].name"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+			}, virtv1alpha1.Disk{
+				Name: "vol-5",
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+						HostPath: "/var/run/spdk/vhost-blk-1.sock",
+					},
+				},
+			}, virtv1alpha1.Volume{
+				Name: "vol-5",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+						ClaimName:  "vol-5",
+						SocketName: "vhost-blk.sock",
+					},
+				},
+			})
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks = append(vm.Spec.Instance.Disks, virtv1alpha1.Disk{
+				Name: "vol-4",
+				IOLimits: &virtv1alpha1.DiskIOLimits{
+					IOPS: 1000,
+				},
+			})
+			vm.Spec.Volumes = append(vm.Spec.Volumes, virtv1alpha1.Volume{
+				Name: "vol-4",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+						HostPath:   "vhost-blk-1.sock",
+						SocketName: "vhost-blk-1.sock",
+					},
+				},
+			})
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[2]", "spec.volumes[3].vhostUser.hostPath", "spec.volumes[3].vhostUser.socketName"},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -296,6 +791,63 @@ func TestValidateVM(t *testing.T) {
 			return vm
 		}(),
 		invalidFields: []string{"spec.instance.disks[0].name"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			bandwidth := resource.MustParse("100Mi")
+			vm.Spec.Instance.Disks[0].IOLimits = &virtv1alpha1.DiskIOLimits{
+				Bandwidth: &bandwidth,
+				IOPS:      1000,
+				IOPSBurst: 5000,
+			}
+			vm.Spec.Instance.DiskIOLimitGroups = []virtv1alpha1.DiskIOLimitGroup{{
+				Name: "group-1",
+				DiskIOLimits: virtv1alpha1.DiskIOLimits{
+					IOPS: 1000,
+				},
+			}}
+			vm.Spec.Instance.Disks[1].IOLimitGroup = "group-1"
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].IOLimits = &virtv1alpha1.DiskIOLimits{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].ioLimits"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].IOLimits = &virtv1alpha1.DiskIOLimits{
+				IOPSBurst: 1000,
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].ioLimits", "spec.instance.disks[0].ioLimits.iopsBurst"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].IOLimitGroup = "group-1"
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].ioLimitGroup"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.DiskIOLimitGroups = []virtv1alpha1.DiskIOLimitGroup{{
+				Name: "group-1",
+				DiskIOLimits: virtv1alpha1.DiskIOLimits{
+					IOPS: 1000,
+				},
+			}}
+			vm.Spec.Instance.Disks[0].IOLimitGroup = "group-1"
+			vm.Spec.Instance.Disks[0].IOLimits = &virtv1alpha1.DiskIOLimits{
+				IOPS: 1000,
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].ioLimits"},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -317,6 +869,128 @@ func TestValidateVM(t *testing.T) {
 			return vm
 		}(),
 		invalidFields: []string{"spec.instance.interfaces[0].mac"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			bandwidth := resource.MustParse("100M")
+			vm.Spec.Instance.Interfaces[0].RateLimit = &virtv1alpha1.InterfaceRateLimit{
+				Bandwidth: &bandwidth,
+				Packets:   10000,
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Annotations = map[string]string{
+				"kubernetes.io/ingress-bandwidth": "10M",
+				"kubernetes.io/egress-bandwidth":  "10M",
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Annotations = map[string]string{
+				"kubernetes.io/ingress-bandwidth": "10M",
+			}
+			return vm
+		}(),
+		invalidFields: []string{"metadata.annotations[kubernetes.io/egress-bandwidth]"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Annotations = map[string]string{
+				"kubernetes.io/ingress-bandwidth": "10M",
+				"kubernetes.io/egress-bandwidth":  "20M",
+			}
+			return vm
+		}(),
+		invalidFields: []string{"metadata.annotations[kubernetes.io/egress-bandwidth]"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Annotations = map[string]string{
+				"kubernetes.io/ingress-bandwidth": "10M",
+			}
+			bandwidth := resource.MustParse("1M")
+			vm.Spec.Instance.Interfaces[0].RateLimit = &virtv1alpha1.InterfaceRateLimit{
+				Bandwidth: &bandwidth,
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.Sockets = 2
+			vm.Spec.Instance.NUMA = &virtv1alpha1.NUMA{
+				Nodes: []virtv1alpha1.NUMANode{{
+					CPUs:   []int{0},
+					Memory: resource.MustParse("512Mi"),
+				}, {
+					CPUs:   []int{1},
+					Memory: resource.MustParse("512Mi"),
+				}},
+			}
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.Sockets = 2
+			vm.Spec.Instance.NUMA = &virtv1alpha1.NUMA{
+				Nodes: []virtv1alpha1.NUMANode{{
+					CPUs:   []int{0},
+					Memory: resource.MustParse("512Mi"),
+				}, {
+					CPUs:   []int{0, 2},
+					Memory: resource.MustParse("256Mi"),
+				}},
+			}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.numa.nodes[1].cpus[0]", "spec.instance.numa.nodes[1].cpus[1]", "spec.instance.numa.nodes"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].NumQueues = 4
+			vm.Spec.Instance.Disks[0].QueueSize = 256
+			vm.Spec.Instance.Interfaces[0].NumQueues = 4
+			vm.Spec.Instance.Interfaces[0].QueueSize = 1024
+			return vm
+		}(),
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Disks[0].QueueSize = 100
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.disks[0].queueSize"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Interfaces[0].NumQueues = 3
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.interfaces[0].numQueues"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Interfaces[0].RateLimit = &virtv1alpha1.InterfaceRateLimit{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.interfaces[0].rateLimit"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.Interfaces[0].RateLimit = &virtv1alpha1.InterfaceRateLimit{
+				Packets: 10000,
+			}
+			vm.Spec.Instance.Interfaces[0].Bridge = nil
+			vm.Spec.Instance.Interfaces[0].SRIOV = &virtv1alpha1.InterfaceSRIOV{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.instance.interfaces[0].rateLimit"},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -462,6 +1136,19 @@ func TestValidateVM(t *testing.T) {
 		}(),
 		oldVM:         validVM.DeepCopy(),
 		invalidFields: []string{"spec.volumes[vol-1]"},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.ThreadsPerCore = 0
+			vm.Spec.RunPolicy = virtv1alpha1.RunPolicyHalted
+			assert.NoError(t, MutateVM(context.Background(), vm, vm.DeepCopy()))
+			return vm
+		}(),
+		oldVM: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Instance.CPU.ThreadsPerCore = 0
+			return vm
+		}(),
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			vm := validVM.DeepCopy()
@@ -493,6 +1180,102 @@ func TestValidateVM(t *testing.T) {
 	}
 }
 
+func TestValidateHostDiskPaths(t *testing.T) {
+	spec := &virtv1alpha1.VirtualMachineSpec{
+		Volumes: []virtv1alpha1.Volume{{
+			Name: "vol-1",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+					Path: "/var/lib/images/ubuntu.img",
+				},
+			},
+		}, {
+			Name: "vol-2",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+					Path: "/var/lib/images/../../../etc/shadow",
+				},
+			},
+		}, {
+			Name: "vol-3",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+					Path: "/var/lib/images-other/ubuntu.img",
+				},
+			},
+		}},
+	}
+
+	tests := []struct {
+		pathPrefixes  []string
+		invalidFields []string
+	}{{
+		pathPrefixes:  nil,
+		invalidFields: []string{"spec.volumes[0].hostDisk.path", "spec.volumes[1].hostDisk.path", "spec.volumes[2].hostDisk.path"},
+	}, {
+		pathPrefixes:  []string{"/var/lib/images/"},
+		invalidFields: []string{"spec.volumes[1].hostDisk.path", "spec.volumes[2].hostDisk.path"},
+	}, {
+		pathPrefixes:  []string{"/"},
+		invalidFields: nil,
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateHostDiskPaths(context.Background(), spec, tc.pathPrefixes, field.NewPath("spec"))
+		assert.Len(t, errs, len(tc.invalidFields))
+		for _, err := range errs {
+			assert.Contains(t, tc.invalidFields, err.Field, err.Detail)
+		}
+	}
+}
+
+func TestValidateVhostUserSocketPaths(t *testing.T) {
+	spec := &virtv1alpha1.VirtualMachineSpec{
+		Volumes: []virtv1alpha1.Volume{{
+			Name: "vol-1",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+					HostPath: "/var/tmp/spdk/vhost-blk-1.sock",
+				},
+			},
+		}, {
+			Name: "vol-2",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+					HostPath: "/var/tmp/spdk/../../../run/containerd/containerd.sock",
+				},
+			},
+		}, {
+			Name: "vol-3",
+			VolumeSource: virtv1alpha1.VolumeSource{
+				VhostUser: &virtv1alpha1.VhostUserVolumeSource{
+					ClaimName:  "vol-3",
+					SocketName: "vhost-blk.sock",
+				},
+			},
+		}},
+	}
+
+	tests := []struct {
+		pathPrefixes  []string
+		invalidFields []string
+	}{{
+		pathPrefixes:  nil,
+		invalidFields: []string{"spec.volumes[0].vhostUser.hostPath", "spec.volumes[1].vhostUser.hostPath"},
+	}, {
+		pathPrefixes:  []string{"/var/tmp/spdk"},
+		invalidFields: []string{"spec.volumes[1].vhostUser.hostPath"},
+	}}
+
+	for _, tc := range tests {
+		errs := ValidateVhostUserSocketPaths(context.Background(), spec, tc.pathPrefixes, field.NewPath("spec"))
+		assert.Len(t, errs, len(tc.invalidFields))
+		for _, err := range errs {
+			assert.Contains(t, tc.invalidFields, err.Field, err.Detail)
+		}
+	}
+}
+
 func TestMutateVM(t *testing.T) {
 	oldVM := &virtv1alpha1.VirtualMachine{
 		Spec: virtv1alpha1.VirtualMachineSpec{
@@ -527,6 +1310,23 @@ func TestMutateVM(t *testing.T) {
 		assert: func(vm *virtv1alpha1.VirtualMachine) {
 			assert.Equal(t, uint32(1), vm.Spec.Instance.CPU.CoresPerSocket)
 		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			return oldVM.DeepCopy()
+		}(),
+		assert: func(vm *virtv1alpha1.VirtualMachine) {
+			assert.Equal(t, uint32(1), vm.Spec.Instance.CPU.ThreadsPerCore)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			return oldVM.DeepCopy()
+		}(),
+		oldVM: func() *virtv1alpha1.VirtualMachine {
+			return oldVM.DeepCopy()
+		}(),
+		assert: func(vm *virtv1alpha1.VirtualMachine) {
+			assert.Equal(t, uint32(0), vm.Spec.Instance.CPU.ThreadsPerCore)
+		},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			return oldVM.DeepCopy()
@@ -571,6 +1371,20 @@ func TestMutateVM(t *testing.T) {
 			assert.True(t, vm.Spec.Resources.Requests["hugepages-1Gi"].Equal(resource.MustParse("1Gi")))
 			assert.True(t, vm.Spec.Resources.Requests[corev1.ResourceMemory].Equal(resource.MustParse(memoryOverhead)))
 		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := oldVM.DeepCopy()
+			vm.Spec.Resources.Requests = nil
+			vm.Spec.Instance.Memory.Hugepages = &virtv1alpha1.Hugepages{
+				PageSize: "2048Ki",
+			}
+			return vm
+		}(),
+		assert: func(vm *virtv1alpha1.VirtualMachine) {
+			assert.Equal(t, "2Mi", vm.Spec.Instance.Memory.Hugepages.PageSize)
+			assert.True(t, vm.Spec.Resources.Limits["hugepages-2Mi"].Equal(resource.MustParse("1Gi")))
+			assert.True(t, vm.Spec.Resources.Requests["hugepages-2Mi"].Equal(resource.MustParse("1Gi")))
+		},
 	}, {
 		vm: func() *virtv1alpha1.VirtualMachine {
 			return oldVM.DeepCopy()
@@ -608,6 +1422,32 @@ func TestMutateVM(t *testing.T) {
 		assert: func(vm *virtv1alpha1.VirtualMachine) {
 			assert.Equal(t, vm.Spec.Instance.Interfaces[0].Masquerade.CIDR, "10.0.2.0/30")
 		},
+	}, {
+		// prerunner uses the UID of the VM as the UUID
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := oldVM.DeepCopy()
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{}
+			return vm
+		}(),
+		assert: func(vm *virtv1alpha1.VirtualMachine) {
+			assert.Empty(t, vm.Spec.Instance.Platform.UUID)
+		},
+	}, {
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := oldVM.DeepCopy()
+			vm.UID = "c1f2a7b6-5d8e-4c3a-9b0f-1e2d3c4b5a69"
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{}
+			return vm
+		}(),
+		oldVM: func() *virtv1alpha1.VirtualMachine {
+			vm := oldVM.DeepCopy()
+			vm.UID = "c1f2a7b6-5d8e-4c3a-9b0f-1e2d3c4b5a69"
+			vm.Spec.Instance.Platform = &virtv1alpha1.Platform{}
+			return vm
+		}(),
+		assert: func(vm *virtv1alpha1.VirtualMachine) {
+			assert.Empty(t, vm.Spec.Instance.Platform.UUID)
+		},
 	}}
 	for _, tc := range tests {
 		err := MutateVM(context.Background(), tc.vm, tc.oldVM)
@@ -109,6 +109,11 @@ func ValidateVMName(ctx context.Context, c client.Client, namespace string, vmNa
 		return errs
 	}
 
+	if vm.Status.VolumeMove != nil {
+		errs = append(errs, field.Forbidden(fieldPath, "VM volume move is in progress"))
+		return errs
+	}
+
 	migratableCondition := meta.FindStatusCondition(vm.Status.Conditions, string(virtv1alpha1.VirtualMachineMigratable))
 	if migratableCondition == nil {
 		errs = append(errs, field.Forbidden(fieldPath, "VM migratable condition status is unknown"))
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+const (
+	VMVMProtectionFinalizer = "virtink.io/vmvm-protection"
+)
+
+type VMVMReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachinevolumemoves,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachinevolumemoves/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachinevolumemoves/finalizers,verbs=update
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachines,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;update;patch
+
+func (r *VMVMReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var vmvm virtv1alpha1.VirtualMachineVolumeMove
+	if err := r.Get(ctx, req.NamespacedName, &vmvm); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	status := vmvm.Status.DeepCopy()
+	if err := r.reconcile(ctx, &vmvm); err != nil {
+		r.Recorder.Eventf(&vmvm, corev1.EventTypeWarning, "FailedReconcile", "Failed to reconcile VMVM: %s", err)
+		return ctrl.Result{}, err
+	}
+
+	if !reflect.DeepEqual(vmvm.Status, status) {
+		if err := r.Status().Update(ctx, &vmvm); err != nil {
+			if apierrors.IsConflict(err) {
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, fmt.Errorf("update VMVM status: %s", err)
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *VMVMReconciler) reconcile(ctx context.Context, vmvm *virtv1alpha1.VirtualMachineVolumeMove) error {
+	if vmvm.DeletionTimestamp == nil && !controllerutil.ContainsFinalizer(vmvm, VMVMProtectionFinalizer) {
+		controllerutil.AddFinalizer(vmvm, VMVMProtectionFinalizer)
+		return r.Client.Update(ctx, vmvm)
+	}
+
+	var vm virtv1alpha1.VirtualMachine
+	vmKey := client.ObjectKey{
+		Name:      vmvm.Spec.VMName,
+		Namespace: vmvm.Namespace,
+	}
+	vmNotFound := false
+	if err := r.Client.Get(ctx, vmKey, &vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			vmNotFound = true
+		} else {
+			return fmt.Errorf("get vm: %s", err)
+		}
+	}
+
+	if vmvm.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(vmvm, VMVMProtectionFinalizer) {
+			return nil
+		}
+
+		if !vmNotFound && vm.Status.VolumeMove != nil && vm.Status.VolumeMove.UID == vmvm.UID {
+			switch vm.Status.VolumeMove.Phase {
+			case virtv1alpha1.VirtualMachineVolumeMoveSwitching:
+				// The source disk is unplugged from the VM, wait for virt-daemon to plug in either the target or the
+				// source volume again.
+				return nil
+			case virtv1alpha1.VirtualMachineVolumeMoveSwitched:
+				// The disk is already backed by the target volume, so the VM spec has to follow.
+				return r.completeVolumeMove(ctx, &vm)
+			default:
+				// Resetting the VM volume move status detaches the target volume from the VM.
+				vm.Status.VolumeMove = nil
+				if err := r.Client.Status().Update(ctx, &vm); err != nil {
+					return fmt.Errorf("reset vm volume move status: %s", err)
+				}
+			}
+		}
+
+		controllerutil.RemoveFinalizer(vmvm, VMVMProtectionFinalizer)
+		return r.Client.Update(ctx, vmvm)
+	}
+
+	if vmvm.Status.Phase == virtv1alpha1.VirtualMachineVolumeMoveSucceeded ||
+		vmvm.Status.Phase == virtv1alpha1.VirtualMachineVolumeMoveFailed {
+		if vmNotFound || !vm.DeletionTimestamp.IsZero() || vm.Status.VolumeMove == nil || vm.Status.VolumeMove.UID != vmvm.UID {
+			return nil
+		}
+
+		vm.Status.VolumeMove = nil
+		if err := r.Client.Status().Update(ctx, &vm); err != nil {
+			return fmt.Errorf("reset vm volume move status: %s", err)
+		}
+		return nil
+	}
+
+	if vmNotFound || !vm.DeletionTimestamp.IsZero() || vm.Status.Phase != virtv1alpha1.VirtualMachineRunning ||
+		(vm.Status.VolumeMove != nil && vm.Status.VolumeMove.UID != vmvm.UID) ||
+		(vm.Status.VolumeMove == nil && vmvm.Status.Phase != "") {
+		vmvm.Status.Phase = virtv1alpha1.VirtualMachineVolumeMoveFailed
+		return nil
+	}
+
+	if vm.Status.VolumeMove == nil {
+		targetVolumeSource := vmvm.Spec.TargetVolume.DeepCopy()
+		if targetVolumeSource.PersistentVolumeClaim != nil {
+			targetVolumeSource.PersistentVolumeClaim.Hotpluggable = true
+		}
+		if targetVolumeSource.DataVolume != nil {
+			targetVolumeSource.DataVolume.Hotpluggable = true
+		}
+		vm.Status.VolumeMove = &virtv1alpha1.VirtualMachineStatusVolumeMove{
+			UID:        vmvm.UID,
+			Phase:      virtv1alpha1.VirtualMachineVolumeMovePending,
+			VolumeName: vmvm.Spec.VolumeName,
+			TargetVolume: virtv1alpha1.Volume{
+				VolumeSource: *targetVolumeSource,
+			},
+		}
+		if err := r.Client.Status().Update(ctx, &vm); err != nil {
+			return fmt.Errorf("set VM volume move status: %s", err)
+		}
+		return nil
+	}
+
+	if vm.Status.VolumeMove.Phase == virtv1alpha1.VirtualMachineVolumeMoveSwitched {
+		if err := r.completeVolumeMove(ctx, &vm); err != nil {
+			return err
+		}
+	}
+
+	vmvm.Status.Phase = vm.Status.VolumeMove.Phase
+
+	return nil
+}
+
+// completeVolumeMove updates the moved volume of a Switched VM to reference the target volume, then moves
+// the VM volume move status to Succeeded.
+func (r *VMVMReconciler) completeVolumeMove(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	for i := range vm.Spec.Volumes {
+		if vm.Spec.Volumes[i].Name != vm.Status.VolumeMove.VolumeName {
+			continue
+		}
+		targetVolumeSource := vm.Status.VolumeMove.TargetVolume.VolumeSource
+		if !reflect.DeepEqual(vm.Spec.Volumes[i].VolumeSource, targetVolumeSource) {
+			vm.Spec.Volumes[i].VolumeSource = targetVolumeSource
+			if err := r.Client.Update(ctx, vm); err != nil {
+				return fmt.Errorf("update VM volume: %s", err)
+			}
+			return nil
+		}
+	}
+
+	vm.Status.VolumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveSucceeded
+	if err := r.Client.Status().Update(ctx, vm); err != nil {
+		return fmt.Errorf("update VM volume move status: %s", err)
+	}
+	return nil
+}
+
+func (r *VMVMReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &virtv1alpha1.VirtualMachineVolumeMove{}, ".metadata.uid", func(obj client.Object) []string {
+		vmvm := obj.(*virtv1alpha1.VirtualMachineVolumeMove)
+		return []string{string(vmvm.UID)}
+	}); err != nil {
+		return fmt.Errorf("index VMVM by UID: %s", err)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&virtv1alpha1.VirtualMachineVolumeMove{}).
+		Watches(&virtv1alpha1.VirtualMachine{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			vm := obj.(*virtv1alpha1.VirtualMachine)
+			if vm.Status.VolumeMove == nil || vm.Status.VolumeMove.UID == "" {
+				return nil
+			}
+
+			var vmvmList virtv1alpha1.VirtualMachineVolumeMoveList
+			if err := r.Client.List(context.Background(), &vmvmList, client.MatchingFields{".metadata.uid": string(vm.Status.VolumeMove.UID)}); err != nil {
+				return nil
+			}
+
+			var requests []reconcile.Request
+			for _, vmvm := range vmvmList.Items {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Namespace: vmvm.Namespace,
+						Name:      vmvm.Name,
+					},
+				})
+			}
+			return requests
+		})).
+		Complete(r)
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+func TestReconcileVMVM(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(virtv1alpha1.AddToScheme(scheme))
+
+	targetVolumeSource := virtv1alpha1.VolumeSource{
+		PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+			ClaimName: "data-new",
+		},
+	}
+
+	hotpluggableTargetVolumeSource := targetVolumeSource.DeepCopy()
+	hotpluggableTargetVolumeSource.PersistentVolumeClaim.Hotpluggable = true
+
+	validVMVM := &virtv1alpha1.VirtualMachineVolumeMove{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-vmvm",
+			Namespace:  "default",
+			UID:        "test-vmvm-uid",
+			Finalizers: []string{VMVMProtectionFinalizer},
+		},
+		Spec: virtv1alpha1.VirtualMachineVolumeMoveSpec{
+			VMName:       "test-vm",
+			VolumeName:   "data",
+			TargetVolume: targetVolumeSource,
+		},
+	}
+
+	validVM := &virtv1alpha1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-vm",
+			Namespace: "default",
+		},
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Volumes: []virtv1alpha1.Volume{{
+				Name: "data",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+						Hotpluggable: true,
+						ClaimName:    "data",
+					},
+				},
+			}},
+		},
+		Status: virtv1alpha1.VirtualMachineStatus{
+			Phase: virtv1alpha1.VirtualMachineRunning,
+		},
+	}
+
+	movingVM := func(phase virtv1alpha1.VirtualMachineVolumeMovePhase) *virtv1alpha1.VirtualMachine {
+		vm := validVM.DeepCopy()
+		vm.Status.VolumeMove = &virtv1alpha1.VirtualMachineStatusVolumeMove{
+			UID:        validVMVM.UID,
+			Phase:      phase,
+			VolumeName: "data",
+			TargetVolume: virtv1alpha1.Volume{
+				Name:         "data-abcde",
+				VolumeSource: *hotpluggableTargetVolumeSource.DeepCopy(),
+			},
+		}
+		return vm
+	}
+
+	deletingVMVM := func() *virtv1alpha1.VirtualMachineVolumeMove {
+		vmvm := validVMVM.DeepCopy()
+		now := metav1.Now()
+		vmvm.DeletionTimestamp = &now
+		return vmvm
+	}
+
+	tests := []struct {
+		vmvm *virtv1alpha1.VirtualMachineVolumeMove
+		vm   *virtv1alpha1.VirtualMachine
+
+		expectedFinalizers     []string
+		expectedDeleted        bool
+		expectedVMVMPhase      virtv1alpha1.VirtualMachineVolumeMovePhase
+		expectedVMPhase        virtv1alpha1.VirtualMachineVolumeMovePhase
+		expectedVMVolumeSource *virtv1alpha1.VolumeSource
+	}{{
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Finalizers = nil
+			return vmvm
+		}(),
+		vm:                 validVM,
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+	}, {
+		vmvm:               validVMVM,
+		vm:                 validVM,
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMPhase:    virtv1alpha1.VirtualMachineVolumeMovePending,
+	}, {
+		vmvm:               validVMVM,
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:  virtv1alpha1.VirtualMachineVolumeMoveFailed,
+	}, {
+		vmvm: validVMVM,
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Status.Phase = virtv1alpha1.VirtualMachineScheduled
+			return vm
+		}(),
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:  virtv1alpha1.VirtualMachineVolumeMoveFailed,
+	}, {
+		vmvm:               validVMVM,
+		vm:                 movingVM(virtv1alpha1.VirtualMachineVolumeMoveCopying),
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:  virtv1alpha1.VirtualMachineVolumeMoveCopying,
+		expectedVMPhase:    virtv1alpha1.VirtualMachineVolumeMoveCopying,
+	}, {
+		vmvm:                   validVMVM,
+		vm:                     movingVM(virtv1alpha1.VirtualMachineVolumeMoveSwitched),
+		expectedFinalizers:     []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:      virtv1alpha1.VirtualMachineVolumeMoveSwitched,
+		expectedVMPhase:        virtv1alpha1.VirtualMachineVolumeMoveSwitched,
+		expectedVMVolumeSource: hotpluggableTargetVolumeSource,
+	}, {
+		vmvm: validVMVM,
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := movingVM(virtv1alpha1.VirtualMachineVolumeMoveSwitched)
+			vm.Spec.Volumes[0].VolumeSource = vm.Status.VolumeMove.TargetVolume.VolumeSource
+			return vm
+		}(),
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:  virtv1alpha1.VirtualMachineVolumeMoveSucceeded,
+		expectedVMPhase:    virtv1alpha1.VirtualMachineVolumeMoveSucceeded,
+	}, {
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Status.Phase = virtv1alpha1.VirtualMachineVolumeMoveSucceeded
+			return vmvm
+		}(),
+		vm:                 movingVM(virtv1alpha1.VirtualMachineVolumeMoveSucceeded),
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMVMPhase:  virtv1alpha1.VirtualMachineVolumeMoveSucceeded,
+	}, {
+		vmvm:            deletingVMVM(),
+		vm:              movingVM(virtv1alpha1.VirtualMachineVolumeMoveCopying),
+		expectedDeleted: true,
+	}, {
+		vmvm:            deletingVMVM(),
+		expectedDeleted: true,
+	}, {
+		vmvm:               deletingVMVM(),
+		vm:                 movingVM(virtv1alpha1.VirtualMachineVolumeMoveSwitching),
+		expectedFinalizers: []string{VMVMProtectionFinalizer},
+		expectedVMPhase:    virtv1alpha1.VirtualMachineVolumeMoveSwitching,
+	}, {
+		vmvm:                   deletingVMVM(),
+		vm:                     movingVM(virtv1alpha1.VirtualMachineVolumeMoveSwitched),
+		expectedFinalizers:     []string{VMVMProtectionFinalizer},
+		expectedVMPhase:        virtv1alpha1.VirtualMachineVolumeMoveSwitched,
+		expectedVMVolumeSource: hotpluggableTargetVolumeSource,
+	}}
+
+	for i, tc := range tests {
+		objs := []client.Object{tc.vmvm.DeepCopy()}
+		if tc.vm != nil {
+			objs = append(objs, tc.vm.DeepCopy())
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+			WithStatusSubresource(&virtv1alpha1.VirtualMachine{}, &virtv1alpha1.VirtualMachineVolumeMove{}).Build()
+		r := &VMVMReconciler{
+			Client:   c,
+			Scheme:   scheme,
+			Recorder: record.NewFakeRecorder(10),
+		}
+
+		var vmvm virtv1alpha1.VirtualMachineVolumeMove
+		assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(tc.vmvm), &vmvm), "case %d", i)
+		assert.NoError(t, r.reconcile(context.Background(), &vmvm), "case %d", i)
+
+		var actualVMVM virtv1alpha1.VirtualMachineVolumeMove
+		err := c.Get(context.Background(), client.ObjectKeyFromObject(tc.vmvm), &actualVMVM)
+		if tc.expectedDeleted {
+			assert.True(t, apierrors.IsNotFound(err), "case %d", i)
+		} else {
+			assert.NoError(t, err, "case %d", i)
+			assert.Equal(t, tc.expectedFinalizers, actualVMVM.Finalizers, "case %d", i)
+			assert.Equal(t, tc.expectedVMVMPhase, vmvm.Status.Phase, "case %d", i)
+		}
+
+		if tc.vm == nil {
+			continue
+		}
+		var actualVM virtv1alpha1.VirtualMachine
+		assert.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: tc.vm.Name, Namespace: tc.vm.Namespace}, &actualVM), "case %d", i)
+		if tc.expectedVMPhase == "" {
+			assert.Nil(t, actualVM.Status.VolumeMove, "case %d", i)
+		} else if assert.NotNil(t, actualVM.Status.VolumeMove, "case %d", i) {
+			assert.Equal(t, tc.expectedVMPhase, actualVM.Status.VolumeMove.Phase, "case %d", i)
+			assert.Equal(t, validVMVM.UID, actualVM.Status.VolumeMove.UID, "case %d", i)
+		}
+		if tc.expectedVMVolumeSource != nil {
+			assert.Equal(t, *tc.expectedVMVolumeSource, actualVM.Spec.Volumes[0].VolumeSource, "case %d", i)
+		}
+	}
+}
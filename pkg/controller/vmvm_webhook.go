@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/r3labs/diff/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-v1alpha1-virtualmachinevolumemove,mutating=false,failurePolicy=fail,sideEffects=None,groups=virt.virtink.smartx.com,resources=virtualmachinevolumemoves,verbs=create;update,versions=v1alpha1,name=validate.virtualmachinevolumemove.v1alpha1.virt.virtink.smartx.com,admissionReviewVersions={v1,v1beta1}
+
+type VMVMValidator struct {
+	client.Client
+	decoder admission.Decoder
+}
+
+var _ admission.Handler = &VMVMValidator{}
+
+func (h *VMVMValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	h.decoder = admission.NewDecoder(mgr.GetScheme())
+
+	mgr.GetWebhookServer().Register("/validate-v1alpha1-virtualmachinevolumemove", &webhook.Admission{
+		Handler: h,
+	})
+	return nil
+}
+
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachines,verbs=get;list
+// +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachines/status,verbs=get
+
+func (h *VMVMValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var vmvm virtv1alpha1.VirtualMachineVolumeMove
+	if err := h.decoder.Decode(req, &vmvm); err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("unmarshal VMVM: %s", err))
+	}
+
+	var errs field.ErrorList
+	switch req.Operation {
+	case admissionv1.Create:
+		errs = ValidateVMVM(ctx, h.Client, &vmvm, nil)
+	case admissionv1.Update:
+		var oldVMVM virtv1alpha1.VirtualMachineVolumeMove
+		if err := h.decoder.DecodeRaw(req.OldObject, &oldVMVM); err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf("unmarshal old VMVM: %s", err))
+		}
+
+		changes, err := diff.Diff(oldVMVM.Spec, vmvm.Spec, diff.SliceOrdering(true))
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, fmt.Errorf("diff VMVM: %s", err))
+		}
+
+		if len(changes) != 0 {
+			errs = append(errs, field.Forbidden(field.NewPath("spec"), "VMVM spec may not be updated"))
+		}
+	default:
+		return admission.Allowed("")
+	}
+
+	if len(errs) > 0 {
+		return webhook.Denied(errs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+func ValidateVMVM(ctx context.Context, c client.Client, vmvm *virtv1alpha1.VirtualMachineVolumeMove, oldVMVM *virtv1alpha1.VirtualMachineVolumeMove) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, ValidateVMVMSpec(ctx, c, vmvm.Namespace, &vmvm.Spec, field.NewPath("spec"))...)
+	return errs
+}
+
+func ValidateVMVMSpec(ctx context.Context, c client.Client, namespace string, spec *virtv1alpha1.VirtualMachineVolumeMoveSpec, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if spec == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	if spec.VMName == "" {
+		errs = append(errs, field.Required(fieldPath.Child("vmName"), ""))
+		return errs
+	}
+
+	vmKey := client.ObjectKey{Namespace: namespace, Name: spec.VMName}
+	var vm virtv1alpha1.VirtualMachine
+	if err := c.Get(ctx, vmKey, &vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			errs = append(errs, field.NotFound(fieldPath.Child("vmName"), spec.VMName))
+		} else {
+			errs = append(errs, field.InternalError(fieldPath.Child("vmName"), err))
+		}
+		return errs
+	}
+
+	if vm.Status.Phase != virtv1alpha1.VirtualMachineRunning {
+		errs = append(errs, field.Forbidden(fieldPath.Child("vmName"), "VM is not running"))
+	}
+	if vm.Status.Migration != nil {
+		errs = append(errs, field.Forbidden(fieldPath.Child("vmName"), "VM migration is in progress"))
+	}
+	if vm.Status.VolumeMove != nil {
+		errs = append(errs, field.Forbidden(fieldPath.Child("vmName"), "VM volume move is in progress"))
+	}
+
+	errs = append(errs, ValidateVMVMVolumeName(ctx, &vm, spec.VolumeName, fieldPath.Child("volumeName"))...)
+	errs = append(errs, ValidateVMVMTargetVolume(ctx, &vm, &spec.TargetVolume, fieldPath.Child("targetVolume"))...)
+	return errs
+}
+
+func ValidateVMVMVolumeName(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volumeName string, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if volumeName == "" {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	var volume *virtv1alpha1.Volume
+	for i := range vm.Spec.Volumes {
+		if vm.Spec.Volumes[i].Name == volumeName {
+			volume = &vm.Spec.Volumes[i]
+		}
+	}
+	if volume == nil {
+		errs = append(errs, field.NotFound(fieldPath, volumeName))
+		return errs
+	}
+
+	if !volume.IsHotpluggable() {
+		errs = append(errs, field.Forbidden(fieldPath, "only hotpluggable volume may be moved, since its disk is replugged"))
+	}
+
+	for _, fs := range vm.Spec.Instance.FileSystems {
+		if fs.Name == volumeName {
+			errs = append(errs, field.Forbidden(fieldPath, "volume used by file system may not be moved"))
+		}
+	}
+	return errs
+}
+
+func ValidateVMVMTargetVolume(ctx context.Context, vm *virtv1alpha1.VirtualMachine, source *virtv1alpha1.VolumeSource, fieldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		errs = append(errs, field.Required(fieldPath, ""))
+		return errs
+	}
+
+	errs = append(errs, ValidateVolumeSource(ctx, source, fieldPath)...)
+	if source.PersistentVolumeClaim == nil && source.DataVolume == nil {
+		errs = append(errs, field.Forbidden(fieldPath, "target volume must be a persistentVolumeClaim or dataVolume"))
+		return errs
+	}
+
+	targetVolume := virtv1alpha1.Volume{VolumeSource: *source}
+	for _, volume := range vm.Spec.Volumes {
+		if pvcName := volume.PVCName(); pvcName != "" && pvcName == targetVolume.PVCName() {
+			errs = append(errs, field.Forbidden(fieldPath, fmt.Sprintf("PVC %q is already used by volume %q", pvcName, volume.Name)))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+)
+
+func TestValidateVMVM(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(virtv1alpha1.AddToScheme(scheme))
+
+	validVMVM := &virtv1alpha1.VirtualMachineVolumeMove{
+		Spec: virtv1alpha1.VirtualMachineVolumeMoveSpec{
+			VMName:     "test-vm",
+			VolumeName: "data",
+			TargetVolume: virtv1alpha1.VolumeSource{
+				PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "data-new",
+				},
+			},
+		},
+	}
+
+	validVM := &virtv1alpha1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-vm",
+		},
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Volumes: []virtv1alpha1.Volume{{
+				Name: "data",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+						Hotpluggable: true,
+						ClaimName:    "data",
+					},
+				},
+			}},
+		},
+		Status: virtv1alpha1.VirtualMachineStatus{
+			Phase: virtv1alpha1.VirtualMachineRunning,
+		},
+	}
+
+	tests := []struct {
+		vmvm          *virtv1alpha1.VirtualMachineVolumeMove
+		vm            *virtv1alpha1.VirtualMachine
+		invalidFields []string
+	}{{
+		vmvm: validVMVM,
+		vm:   validVM,
+	}, {
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Spec.VMName = ""
+			return vmvm
+		}(),
+		vm:            validVM,
+		invalidFields: []string{"spec.vmName"},
+	}, {
+		vmvm: validVMVM,
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Status.Phase = virtv1alpha1.VirtualMachineScheduled
+			return vm
+		}(),
+		invalidFields: []string{"spec.vmName"},
+	}, {
+		vmvm: validVMVM,
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Status.VolumeMove = &virtv1alpha1.VirtualMachineStatusVolumeMove{}
+			return vm
+		}(),
+		invalidFields: []string{"spec.vmName"},
+	}, {
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Spec.VolumeName = "not-exist"
+			return vmvm
+		}(),
+		vm:            validVM,
+		invalidFields: []string{"spec.volumeName"},
+	}, {
+		vmvm: validVMVM,
+		vm: func() *virtv1alpha1.VirtualMachine {
+			vm := validVM.DeepCopy()
+			vm.Spec.Volumes[0].PersistentVolumeClaim.Hotpluggable = false
+			return vm
+		}(),
+		invalidFields: []string{"spec.volumeName"},
+	}, {
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Spec.TargetVolume = virtv1alpha1.VolumeSource{
+				ContainerDisk: &virtv1alpha1.ContainerDiskVolumeSource{
+					Image: "test",
+				},
+			}
+			return vmvm
+		}(),
+		vm:            validVM,
+		invalidFields: []string{"spec.targetVolume"},
+	}, {
+		vmvm: func() *virtv1alpha1.VirtualMachineVolumeMove {
+			vmvm := validVMVM.DeepCopy()
+			vmvm.Spec.TargetVolume.PersistentVolumeClaim.ClaimName = "data"
+			return vmvm
+		}(),
+		vm:            validVM,
+		invalidFields: []string{"spec.targetVolume"},
+	}}
+
+	for _, tc := range tests {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.vm).Build()
+		errs := ValidateVMVM(context.Background(), c, tc.vmvm, nil)
+		if len(tc.invalidFields) == 0 {
+			assert.Empty(t, errs)
+		}
+		for _, err := range errs {
+			assert.Contains(t, tc.invalidFields, err.Field)
+		}
+	}
+}
@@ -35,6 +35,25 @@ func Get() (CPUSet, error) {
 	return Parse(strings.TrimSpace(string(b)))
 }
 
+func GetNUMANode(cpu int) (int, error) {
+	nodePaths, err := filepath.Glob(filepath.Join("/sys/devices/system/cpu", fmt.Sprintf("cpu%d", cpu), "node*"))
+	if err != nil {
+		return 0, fmt.Errorf("glob NUMA node: %s", err)
+	}
+	if len(nodePaths) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(strings.TrimPrefix(filepath.Base(nodePaths[0]), "node"))
+}
+
+func GetThreadSiblings(cpu int) (CPUSet, error) {
+	b, err := os.ReadFile(filepath.Join("/sys/devices/system/cpu", fmt.Sprintf("cpu%d", cpu), "topology", "thread_siblings_list"))
+	if err != nil {
+		return nil, fmt.Errorf("read thread siblings file: %s", err)
+	}
+	return Parse(strings.TrimSpace(string(b)))
+}
+
 func Parse(s string) (CPUSet, error) {
 	b := NewBuilder()
 
@@ -10,13 +10,17 @@ import (
 
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/configs"
+	"golang.org/x/sys/unix"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cpuset"
 	"github.com/smartxworks/virtink/pkg/daemon/pid"
 )
 
 type Manager interface {
 	Set(ctx context.Context, vm *virtv1alpha1.VirtualMachine, r *configs.Resources) error
+	GetCPUSet() (cpuset.CPUSet, error)
+	GetAllPids() ([]int, error)
 }
 
 func NewManager(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (Manager, error) {
@@ -65,3 +69,28 @@ func NewManager(ctx context.Context, vm *virtv1alpha1.VirtualMachine) (Manager,
 		return newV1Manager(cg, cgroupPaths)
 	}
 }
+
+// SetEmulatorThreadAffinity pins all threads in the cgroup, except vCPU threads of Cloud Hypervisor, to the given CPUs
+func SetEmulatorThreadAffinity(m Manager, cpus cpuset.CPUSet) error {
+	var cpuSet unix.CPUSet
+	for cpu := range cpus {
+		cpuSet.Set(cpu)
+	}
+
+	pids, err := m.GetAllPids()
+	if err != nil {
+		return fmt.Errorf("get pids: %s", err)
+	}
+	for _, p := range pids {
+		_, tids, err := pid.GetThreadIDs(p)
+		if err != nil {
+			return err
+		}
+		for _, tid := range tids {
+			if err := unix.SchedSetaffinity(tid, &cpuSet); err != nil && err != unix.ESRCH {
+				return fmt.Errorf("set affinity of thread %d: %s", tid, err)
+			}
+		}
+	}
+	return nil
+}
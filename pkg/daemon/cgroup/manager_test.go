@@ -0,0 +1,62 @@
+package cgroup
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cpuset"
+)
+
+type fakeManager struct {
+	pids []int
+	err  error
+}
+
+func (m *fakeManager) Set(ctx context.Context, vm *virtv1alpha1.VirtualMachine, r *configs.Resources) error {
+	return nil
+}
+
+func (m *fakeManager) GetCPUSet() (cpuset.CPUSet, error) {
+	return nil, nil
+}
+
+func (m *fakeManager) GetAllPids() ([]int, error) {
+	return m.pids, m.err
+}
+
+func TestSetEmulatorThreadAffinity(t *testing.T) {
+	var allowed unix.CPUSet
+	assert.NoError(t, unix.SchedGetaffinity(0, &allowed))
+	cpu := -1
+	for i := 0; i < len(allowed)*64; i++ {
+		if allowed.IsSet(i) {
+			cpu = i
+			break
+		}
+	}
+
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	assert.NoError(t, SetEmulatorThreadAffinity(&fakeManager{pids: []int{cmd.Process.Pid}}, cpuset.NewCPUSet(cpu)))
+	var actual unix.CPUSet
+	assert.NoError(t, unix.SchedGetaffinity(cmd.Process.Pid, &actual))
+	assert.Equal(t, 1, actual.Count())
+	assert.True(t, actual.IsSet(cpu))
+
+	// exited processes are skipped
+	assert.NoError(t, SetEmulatorThreadAffinity(&fakeManager{pids: []int{1 << 30}}, cpuset.NewCPUSet(cpu)))
+
+	assert.Error(t, SetEmulatorThreadAffinity(&fakeManager{err: fmt.Errorf("cgroup removed")}, cpuset.NewCPUSet(cpu)))
+}
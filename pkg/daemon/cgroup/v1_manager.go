@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/cgroups/devices"
@@ -11,6 +12,7 @@ import (
 	"github.com/opencontainers/runc/libcontainer/configs"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cpuset"
 )
 
 type v1Manager struct {
@@ -52,3 +54,16 @@ func (m *v1Manager) Set(ctx context.Context, vm *virtv1alpha1.VirtualMachine, r
 	resource.Devices = append(resource.Devices, deviceRules...)
 	return m.Manager.Set(&resource)
 }
+
+func (m *v1Manager) GetCPUSet() (cpuset.CPUSet, error) {
+	cpusetPath, ok := m.GetPaths()["cpuset"]
+	if !ok {
+		return nil, fmt.Errorf("cpuset subsystem's path is not defined for this manager")
+	}
+
+	cpus, err := cgroups.ReadFile(cpusetPath, "cpuset.cpus")
+	if err != nil {
+		return nil, fmt.Errorf("read cpuset.cpus: %v", err)
+	}
+	return cpuset.Parse(strings.TrimSpace(cpus))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/opencontainers/runc/libcontainer/devices"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cpuset"
 )
 
 type v2Manager struct {
@@ -58,6 +59,14 @@ func (m *v2Manager) Set(ctx context.Context, vm *virtv1alpha1.VirtualMachine, r
 	return nil
 }
 
+func (m *v2Manager) GetCPUSet() (cpuset.CPUSet, error) {
+	cpus, err := cgroups.ReadFile(m.cgroupPath, "cpuset.cpus.effective")
+	if err != nil {
+		return nil, fmt.Errorf("read cpuset.cpus.effective: %s", err)
+	}
+	return cpuset.Parse(strings.TrimSpace(cpus))
+}
+
 func (m *v2Manager) generateDeviceRuleForVM(ctx context.Context, vm *virtv1alpha1.VirtualMachine) ([]*devices.Rule, error) {
 	deviceRules := []*devices.Rule{{
 		Type:        devices.CharDevice,
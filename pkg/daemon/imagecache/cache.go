@@ -0,0 +1,217 @@
+package imagecache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultDir is the node directory of the image cache, which is populated by virt-daemon with kernels and disks
+	// of containerDisk volumes copied by init containers of VM Pods, each in an entry directory named after the image
+	// ID reported by the container runtime. VM Pods using an entry are recorded in its refs directory with their UIDs.
+	// VM Pods mount the cache read-only at the same path.
+	DefaultDir = "/var/lib/virtink/image-cache"
+
+	// EntryFileName is the name of the file in the volume of an init container, in which virt-daemon writes the path
+	// of the cache entry of its image if the image is cached, or once the files copied by the init container are
+	// added to the cache.
+	EntryFileName = "image-cache-entry"
+
+	// MissFileName is the name of the file which virt-daemon creates in the volume of an init container if its image
+	// is not cached, in which case the init container copies the files.
+	MissFileName = "image-cache-miss"
+
+	copyChunkSize = 1024 * 1024
+)
+
+var qcow2Magic = []byte{'Q', 'F', 'I', 0xfb}
+
+// GetEntryPath returns the path of the entry of an image of kind in the image cache in dir. Image IDs are digests of
+// the image content, so images with the same ID always come with the same files.
+func GetEntryPath(dir string, kind string, imageID string) string {
+	digest := sha256.Sum256([]byte(imageID))
+	return filepath.Join(dir, fmt.Sprintf("%s-%s", kind, hex.EncodeToString(digest[:])))
+}
+
+// Lookup records the Pod using the entry of an image in the image cache in dir, and returns the path of the entry,
+// or an empty string if the image is not cached.
+func Lookup(dir string, kind string, imageID string, podUID types.UID) (string, error) {
+	unlock, err := lockShared(dir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	entryPath := GetEntryPath(dir, kind, imageID)
+	if _, err := os.Stat(entryPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("stat entry: %s", err)
+	}
+	if err := addRef(entryPath, podUID); err != nil {
+		return "", err
+	}
+	return entryPath, nil
+}
+
+// Add adds files of an image to the image cache in dir, unless the image is already cached, and records the Pod using
+// the entry. Files are named after their base names in the entry. The path of the entry is returned.
+func Add(dir string, kind string, imageID string, paths []string, podUID types.UID) (string, error) {
+	unlock, err := lockShared(dir)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	entryPath := GetEntryPath(dir, kind, imageID)
+	if _, err := os.Stat(entryPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("stat entry: %s", err)
+		}
+		if err := populateEntry(dir, entryPath, paths); err != nil {
+			return "", fmt.Errorf("populate entry: %s", err)
+		}
+	}
+	if err := addRef(entryPath, podUID); err != nil {
+		return "", err
+	}
+	return entryPath, nil
+}
+
+// IsQCOW2 returns whether the file at path is a QCOW2 image.
+func IsQCOW2(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(qcow2Magic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(magic, qcow2Magic), nil
+}
+
+// lockShared locks the image cache in dir shared against garbage collection, which locks it exclusively.
+func lockShared(dir string) (func(), error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create image cache dir: %s", err)
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open image cache dir: %s", err)
+	}
+	if err := unix.Flock(int(d.Fd()), unix.LOCK_SH); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("lock image cache: %s", err)
+	}
+	return func() {
+		unix.Flock(int(d.Fd()), unix.LOCK_UN)
+		d.Close()
+	}, nil
+}
+
+func addRef(entryPath string, podUID types.UID) error {
+	if err := os.WriteFile(filepath.Join(entryPath, "refs", string(podUID)), nil, 0644); err != nil {
+		return fmt.Errorf("create ref: %s", err)
+	}
+	now := time.Now()
+	if err := os.Chtimes(entryPath, now, now); err != nil {
+		return fmt.Errorf("touch entry: %s", err)
+	}
+	return nil
+}
+
+func populateEntry(dir string, entryPath string, paths []string) error {
+	tmp, err := os.MkdirTemp(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if err := copySparseFile(path, filepath.Join(tmp, filepath.Base(path))); err != nil {
+			return fmt.Errorf("copy %q: %s", path, err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(tmp, "refs"), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, entryPath); err != nil {
+		// another VM Pod may have populated the entry meanwhile
+		if _, statErr := os.Stat(entryPath); statErr == nil {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// copySparseFile copies source to target, leaving holes in target for zero chunks, since disks are usually sparse.
+func copySparseFile(source string, target string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	buf := make([]byte, copyChunkSize)
+	zero := make([]byte, copyChunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			if !bytes.Equal(buf[:n], zero[:n]) {
+				if _, err := dst.WriteAt(buf[:n], offset); err != nil {
+					return err
+				}
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := dst.Truncate(offset); err != nil {
+		return err
+	}
+	return dst.Close()
+}
+
+// WriteEntryFile writes the path of a cache entry to the entry file in the volume dir of an init container. The file
+// is replaced atomically, since init containers and prerunner poll for it.
+func WriteEntryFile(dir string, entryPath string) error {
+	entryFilePath := filepath.Join(dir, EntryFileName)
+	tmpPath := entryFilePath + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(entryPath), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, entryFilePath)
+}
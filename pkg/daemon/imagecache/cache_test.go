@@ -0,0 +1,106 @@
+package imagecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const imageID = "docker.io/smartxworks/virtink-kernel-5.15.12@sha256:8bd5ab1b5a5b3bd6c5d7b8cf3e5a2ba7ea5b1d5b1c4e1e5bd2b8d3a6a0e5c7b1"
+
+func TestAdd(t *testing.T) {
+	dir := t.TempDir()
+	podDir := t.TempDir()
+
+	vmlinux := filepath.Join(podDir, "vmlinux")
+	initrd := filepath.Join(podDir, "initrd")
+	vmlinuxData := append([]byte("vmlinux"), make([]byte, 2*copyChunkSize)...)
+	assert.NoError(t, os.WriteFile(vmlinux, vmlinuxData, 0644))
+	assert.NoError(t, os.WriteFile(initrd, []byte("initrd"), 0644))
+
+	expectedEntryPath := GetEntryPath(dir, "kernel", imageID)
+	assert.Equal(t, dir, filepath.Dir(expectedEntryPath))
+
+	entryPath, err := Lookup(dir, "kernel", imageID, "pod-1")
+	assert.NoError(t, err)
+	assert.Empty(t, entryPath)
+
+	entryPath, err = Add(dir, "kernel", imageID, []string{vmlinux, initrd}, "pod-1")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEntryPath, entryPath)
+
+	actualVMLinux, err := os.ReadFile(filepath.Join(entryPath, "vmlinux"))
+	assert.NoError(t, err)
+	assert.Equal(t, vmlinuxData, actualVMLinux)
+	actualInitrd, err := os.ReadFile(filepath.Join(entryPath, "initrd"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("initrd"), actualInitrd)
+
+	entryPath, err = Lookup(dir, "kernel", imageID, "pod-2")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEntryPath, entryPath)
+
+	// the entry is not repopulated
+	assert.NoError(t, os.WriteFile(vmlinux, []byte("modified"), 0644))
+	entryPath, err = Add(dir, "kernel", imageID, []string{vmlinux, initrd}, "pod-3")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedEntryPath, entryPath)
+	actualVMLinux, err = os.ReadFile(filepath.Join(entryPath, "vmlinux"))
+	assert.NoError(t, err)
+	assert.Equal(t, vmlinuxData, actualVMLinux)
+
+	refs, err := os.ReadDir(filepath.Join(entryPath, "refs"))
+	assert.NoError(t, err)
+	var refNames []string
+	for _, ref := range refs {
+		refNames = append(refNames, ref.Name())
+	}
+	assert.Equal(t, []string{"pod-1", "pod-2", "pod-3"}, refNames)
+
+	names, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, names, 1)
+
+	otherEntryPath, err := Add(dir, "disk", imageID, []string{vmlinux}, "pod-1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, expectedEntryPath, otherEntryPath)
+}
+
+func TestAddMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Add(dir, "disk", imageID, []string{filepath.Join(t.TempDir(), "disk.qcow2")}, "pod-1")
+	assert.Error(t, err)
+
+	names, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestIsQCOW2(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected bool
+	}{{
+		data:     append([]byte{'Q', 'F', 'I', 0xfb, 0, 0, 0, 3}, make([]byte, 512)...),
+		expected: true,
+	}, {
+		data: make([]byte, 512),
+	}, {
+		data: []byte("QF"),
+	}, {
+		data: nil,
+	}}
+
+	for i, tc := range tests {
+		path := filepath.Join(t.TempDir(), "disk.qcow2")
+		assert.NoError(t, os.WriteFile(path, tc.data, 0644), "case %d", i)
+		actual, err := IsQCOW2(path)
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, tc.expected, actual, "case %d", i)
+	}
+
+	_, err := IsQCOW2(filepath.Join(t.TempDir(), "disk.qcow2"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,184 @@
+package imagecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	gcInterval = time.Minute
+	// refGracePeriod keeps refs of VM Pods which are not yet observed by the client cache.
+	refGracePeriod = 5 * time.Minute
+
+	podNodeNameField = "spec.nodeName"
+)
+
+// GarbageCollector evicts least recently used entries of the image cache in Dir which are not used
+// by any Pod on the node, until the cache fits in SizeLimit bytes.
+type GarbageCollector struct {
+	Client    client.Client
+	NodeName  string
+	Dir       string
+	SizeLimit int64
+}
+
+func (gc *GarbageCollector) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, podNodeNameField, podNodeNameIndexFunc); err != nil {
+		return err
+	}
+	return mgr.Add(gc)
+}
+
+func podNodeNameIndexFunc(obj client.Object) []string {
+	return []string{obj.(*corev1.Pod).Spec.NodeName}
+}
+
+func (gc *GarbageCollector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := gc.collect(ctx); err != nil {
+			ctrl.Log.Error(err, "collect image cache")
+		}
+	}, gcInterval)
+	return nil
+}
+
+type entry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+	inUse    bool
+}
+
+func (gc *GarbageCollector) collect(ctx context.Context) error {
+	if err := os.MkdirAll(gc.Dir, 0755); err != nil {
+		return fmt.Errorf("create image cache dir: %s", err)
+	}
+	dir, err := os.Open(gc.Dir)
+	if err != nil {
+		return fmt.Errorf("open image cache dir: %s", err)
+	}
+	defer dir.Close()
+
+	// Entries are populated and refs are recorded with the cache locked shared.
+	if err := unix.Flock(int(dir.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil
+		}
+		return fmt.Errorf("lock image cache: %s", err)
+	}
+	defer unix.Flock(int(dir.Fd()), unix.LOCK_UN)
+
+	var podList corev1.PodList
+	if err := gc.Client.List(ctx, &podList, client.MatchingFields{podNodeNameField: gc.NodeName}); err != nil {
+		return fmt.Errorf("list pods: %s", err)
+	}
+	podUIDs := map[string]bool{}
+	for _, pod := range podList.Items {
+		podUIDs[string(pod.UID)] = true
+	}
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return fmt.Errorf("read image cache dir: %s", err)
+	}
+
+	var entries []*entry
+	var totalSize int64
+	for _, name := range names {
+		path := filepath.Join(gc.Dir, name)
+		if strings.HasPrefix(name, ".tmp-") {
+			// Left by failed populations since the cache is locked exclusively.
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("remove temporary dir %q: %s", path, err)
+			}
+			continue
+		}
+
+		e, err := inspectEntry(path, podUIDs)
+		if err != nil {
+			return fmt.Errorf("inspect entry %q: %s", path, err)
+		}
+		entries = append(entries, e)
+		totalSize += e.size
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, e := range entries {
+		if totalSize <= gc.SizeLimit {
+			break
+		}
+		if e.inUse {
+			continue
+		}
+		if err := os.RemoveAll(e.path); err != nil {
+			return fmt.Errorf("remove entry %q: %s", e.path, err)
+		}
+		ctrl.Log.Info("Evicted image cache entry", "path", e.path, "size", e.size, "lastUsed", e.lastUsed)
+		totalSize -= e.size
+	}
+	return nil
+}
+
+func inspectEntry(path string, podUIDs map[string]bool) (*entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &entry{
+		path:     path,
+		lastUsed: info.ModTime(),
+	}
+
+	refsDir := filepath.Join(path, "refs")
+	refs, err := os.ReadDir(refsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read refs dir: %s", err)
+	}
+	for _, ref := range refs {
+		refInfo, err := ref.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat ref %q: %s", ref.Name(), err)
+		}
+		if podUIDs[ref.Name()] || time.Since(refInfo.ModTime()) < refGracePeriod {
+			e.inUse = true
+			continue
+		}
+		if err := os.Remove(filepath.Join(refsDir, ref.Name())); err != nil {
+			return nil, fmt.Errorf("remove ref %q: %s", ref.Name(), err)
+		}
+	}
+
+	if err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Disks are sparse, so the allocated size is counted instead of the apparent size.
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+			e.size += stat.Blocks * 512
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walk entry: %s", err)
+	}
+	return e, nil
+}
@@ -0,0 +1,194 @@
+package imagecache
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCollect(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	pods := []*corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm-1",
+			Namespace: "default",
+			UID:       "pod-1",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vm-2",
+			Namespace: "default",
+			UID:       "pod-2",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-2",
+		},
+	}}
+
+	type entry struct {
+		name     string
+		size     int
+		lastUsed time.Duration
+		refs     map[string]time.Duration
+	}
+
+	tests := []struct {
+		entries []entry
+		// sizeLimit is in the number of entries
+		sizeLimit       int64
+		expectedEntries []string
+		expectedRefs    map[string][]string
+	}{{
+		entries: []entry{{
+			name:     "disk-a",
+			size:     4096,
+			lastUsed: 3 * time.Hour,
+		}, {
+			name:     "disk-b",
+			size:     4096,
+			lastUsed: 2 * time.Hour,
+		}, {
+			name:     "disk-c",
+			size:     4096,
+			lastUsed: time.Hour,
+		}},
+		sizeLimit:       3,
+		expectedEntries: []string{"disk-a", "disk-b", "disk-c"},
+	}, {
+		entries: []entry{{
+			name:     "disk-a",
+			size:     4096,
+			lastUsed: 3 * time.Hour,
+		}, {
+			name:     "disk-b",
+			size:     4096,
+			lastUsed: 2 * time.Hour,
+		}, {
+			name:     "disk-c",
+			size:     4096,
+			lastUsed: time.Hour,
+		}},
+		sizeLimit:       2,
+		expectedEntries: []string{"disk-b", "disk-c"},
+	}, {
+		entries: []entry{{
+			name:     "disk-a",
+			size:     4096,
+			lastUsed: 3 * time.Hour,
+			refs: map[string]time.Duration{
+				"pod-1": time.Hour,
+			},
+		}, {
+			name:     "disk-b",
+			size:     4096,
+			lastUsed: 2 * time.Hour,
+			refs: map[string]time.Duration{
+				// the Pod is on another node
+				"pod-2": time.Hour,
+			},
+		}, {
+			name:     "kernel-c",
+			size:     4096,
+			lastUsed: time.Hour,
+			refs: map[string]time.Duration{
+				// the Pod is not yet observed
+				"pod-3": time.Minute,
+			},
+		}},
+		sizeLimit:       0,
+		expectedEntries: []string{"disk-a", "kernel-c"},
+		expectedRefs: map[string][]string{
+			"disk-a":   {"pod-1"},
+			"kernel-c": {"pod-3"},
+		},
+	}, {
+		entries: []entry{{
+			name:     ".tmp-123456",
+			size:     4096,
+			lastUsed: time.Minute,
+		}},
+		sizeLimit:       3,
+		expectedEntries: nil,
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		now := time.Now()
+		for _, e := range tc.entries {
+			entryPath := filepath.Join(dir, e.name)
+			assert.NoError(t, os.MkdirAll(filepath.Join(entryPath, "refs"), 0755), "case %d", i)
+			data := make([]byte, e.size)
+			for j := range data {
+				data[j] = 1
+			}
+			assert.NoError(t, os.WriteFile(filepath.Join(entryPath, "disk.qcow2"), data, 0644), "case %d", i)
+			for ref, age := range e.refs {
+				refPath := filepath.Join(entryPath, "refs", ref)
+				assert.NoError(t, os.WriteFile(refPath, nil, 0644), "case %d", i)
+				assert.NoError(t, os.Chtimes(refPath, now.Add(-age), now.Add(-age)), "case %d", i)
+			}
+			assert.NoError(t, os.Chtimes(entryPath, now.Add(-e.lastUsed), now.Add(-e.lastUsed)), "case %d", i)
+		}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pods[0].DeepCopy(), pods[1].DeepCopy()).
+			WithIndex(&corev1.Pod{}, podNodeNameField, podNodeNameIndexFunc).Build()
+		gc := &GarbageCollector{
+			Client:    c,
+			NodeName:  "node-1",
+			Dir:       dir,
+			SizeLimit: tc.sizeLimit * getAllocatedSize(t, filepath.Join(dir, tc.entries[0].name)),
+		}
+		assert.NoError(t, gc.collect(context.Background()), "case %d", i)
+
+		names, err := os.ReadDir(dir)
+		assert.NoError(t, err, "case %d", i)
+		var actualEntries []string
+		for _, name := range names {
+			actualEntries = append(actualEntries, name.Name())
+		}
+		assert.Equal(t, tc.expectedEntries, actualEntries, "case %d", i)
+
+		for name, expectedRefs := range tc.expectedRefs {
+			refs, err := os.ReadDir(filepath.Join(dir, name, "refs"))
+			assert.NoError(t, err, "case %d", i)
+			var actualRefs []string
+			for _, ref := range refs {
+				actualRefs = append(actualRefs, ref.Name())
+			}
+			assert.Equal(t, expectedRefs, actualRefs, "case %d: refs of %s", i, name)
+		}
+	}
+}
+
+func getAllocatedSize(t *testing.T, path string) int64 {
+	var size int64
+	assert.NoError(t, filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Sys().(*syscall.Stat_t).Blocks * 512
+		return nil
+	}))
+	return size
+}
@@ -0,0 +1,99 @@
+package imagecache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const populateQueueSize = 64
+
+// PopulateRequest is a request to add files copied by an init container into its volume dir to the image cache.
+type PopulateRequest struct {
+	Kind    string
+	ImageID string
+	Dir     string
+	// FileNames are names of the files in Dir, of which only the first one is required.
+	FileNames []string
+	PodUID    types.UID
+}
+
+// Populator adds images to the image cache in Dir in the background, one at a time, so that copying images neither
+// blocks VM reconciliation nor competes with itself for disk I/O. The entry file is written to the volume dir of the
+// init container once its image is added.
+type Populator struct {
+	Dir string
+
+	requests chan PopulateRequest
+	pending  map[string]bool
+	mutex    sync.Mutex
+}
+
+func NewPopulator(dir string) *Populator {
+	return &Populator{
+		Dir:      dir,
+		requests: make(chan PopulateRequest, populateQueueSize),
+		pending:  map[string]bool{},
+	}
+}
+
+func (p *Populator) SetupWithManager(mgr ctrl.Manager) error {
+	return mgr.Add(p)
+}
+
+// Populate queues a request unless one for the same volume dir is pending. Requests are dropped when the queue is
+// full, so callers retry until the entry file is written.
+func (p *Populator) Populate(req PopulateRequest) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.pending[req.Dir] {
+		return
+	}
+	select {
+	case p.requests <- req:
+		p.pending[req.Dir] = true
+	default:
+	}
+}
+
+func (p *Populator) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case req := <-p.requests:
+			if err := p.populate(req); err != nil {
+				ctrl.Log.Error(err, "populate image cache", "dir", req.Dir, "imageID", req.ImageID)
+			}
+			p.mutex.Lock()
+			delete(p.pending, req.Dir)
+			p.mutex.Unlock()
+		}
+	}
+}
+
+func (p *Populator) populate(req PopulateRequest) error {
+	var paths []string
+	for i, name := range req.FileNames {
+		path := filepath.Join(req.Dir, name)
+		if i > 0 {
+			if _, err := os.Stat(path); err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+		}
+		paths = append(paths, path)
+	}
+
+	entryPath, err := Add(p.Dir, req.Kind, req.ImageID, paths, req.PodUID)
+	if err != nil {
+		return err
+	}
+	return WriteEntryFile(req.Dir, entryPath)
+}
@@ -0,0 +1,63 @@
+package imagecache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPopulator(t *testing.T) {
+	dir := t.TempDir()
+	podDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(podDir, "vmlinux"), []byte("vmlinux"), 0644))
+
+	p := NewPopulator(dir)
+	req := PopulateRequest{
+		Kind:      "kernel",
+		ImageID:   imageID,
+		Dir:       podDir,
+		FileNames: []string{"vmlinux", "initrd"},
+		PodUID:    "pod-1",
+	}
+	// pending requests are deduplicated
+	p.Populate(req)
+	p.Populate(req)
+	assert.Len(t, p.requests, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Start(ctx)
+
+	entryFilePath := filepath.Join(podDir, EntryFileName)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(entryFilePath)
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	entryPath, err := os.ReadFile(entryFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, GetEntryPath(dir, "kernel", imageID), string(entryPath))
+	assert.FileExists(t, filepath.Join(string(entryPath), "vmlinux"))
+	assert.NoFileExists(t, filepath.Join(string(entryPath), "initrd"))
+	assert.FileExists(t, filepath.Join(string(entryPath), "refs", "pod-1"))
+
+	// failed requests are retried by callers
+	failedDir := t.TempDir()
+	p.Populate(PopulateRequest{
+		Kind:      "disk",
+		ImageID:   imageID,
+		Dir:       failedDir,
+		FileNames: []string{"disk.qcow2"},
+		PodUID:    "pod-2",
+	})
+	assert.Eventually(t, func() bool {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		return len(p.pending) == 0
+	}, 10*time.Second, 10*time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(failedDir, EntryFileName))
+}
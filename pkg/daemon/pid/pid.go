@@ -3,6 +3,10 @@ package pid
 import (
 	"fmt"
 	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
@@ -25,3 +29,38 @@ func GetPIDBySocket(socket string) (int, error) {
 	}
 	return int(ucred.Pid), nil
 }
+
+var vcpuThreadNameRegexp = regexp.MustCompile(`^vcpu\d+$`)
+
+// GetThreadIDs returns IDs of vCPU threads and other threads of the process respectively,
+// given that Cloud Hypervisor names its vCPU threads as "vcpu<N>"
+func GetThreadIDs(pid int) ([]int, []int, error) {
+	taskDirs, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("read task dir: %s", err)
+	}
+
+	var vcpuTIDs, otherTIDs []int
+	for _, taskDir := range taskDirs {
+		tid, err := strconv.Atoi(taskDir.Name())
+		if err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/comm", pid, tid))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("read task comm: %s", err)
+		}
+		if vcpuThreadNameRegexp.MatchString(strings.TrimSpace(string(comm))) {
+			vcpuTIDs = append(vcpuTIDs, tid)
+		} else {
+			otherTIDs = append(otherTIDs, tid)
+		}
+	}
+	return vcpuTIDs, otherTIDs, nil
+}
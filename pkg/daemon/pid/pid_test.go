@@ -0,0 +1,46 @@
+package pid
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetThreadIDs(t *testing.T) {
+	sleepPath, err := exec.LookPath("sleep")
+	if !assert.NoError(t, err) {
+		return
+	}
+	sleep, err := os.ReadFile(sleepPath)
+	assert.NoError(t, err)
+	// thread names are taken from executable names
+	vcpuPath := filepath.Join(t.TempDir(), "vcpu0")
+	assert.NoError(t, os.WriteFile(vcpuPath, sleep, 0755))
+
+	for _, name := range []string{vcpuPath, sleepPath} {
+		cmd := exec.Command(name, "10")
+		assert.NoError(t, cmd.Start())
+		defer func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}()
+
+		vcpuTIDs, otherTIDs, err := GetThreadIDs(cmd.Process.Pid)
+		assert.NoError(t, err)
+		if name == vcpuPath {
+			assert.Equal(t, []int{cmd.Process.Pid}, vcpuTIDs)
+			assert.Empty(t, otherTIDs)
+		} else {
+			assert.Empty(t, vcpuTIDs)
+			assert.Equal(t, []int{cmd.Process.Pid}, otherTIDs)
+		}
+	}
+
+	vcpuTIDs, otherTIDs, err := GetThreadIDs(1 << 30)
+	assert.NoError(t, err)
+	assert.Empty(t, vcpuTIDs)
+	assert.Empty(t, otherTIDs)
+}
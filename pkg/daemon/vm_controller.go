@@ -1,11 +1,13 @@
 package daemon
 
 import (
+	"bufio"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,22 +17,28 @@ import (
 	"sync"
 	"time"
 
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/moby/sys/mountinfo"
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/devices"
 	"golang.org/x/sys/unix"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
 	"github.com/smartxworks/virtink/pkg/cloudhypervisor"
 	"github.com/smartxworks/virtink/pkg/daemon/cgroup"
+	"github.com/smartxworks/virtink/pkg/daemon/imagecache"
 	"github.com/smartxworks/virtink/pkg/daemon/pid"
 	"github.com/smartxworks/virtink/pkg/tlsutil"
 	"github.com/smartxworks/virtink/pkg/volumeutil"
@@ -48,9 +56,13 @@ type VMReconciler struct {
 	NodeName string
 	NodeIP   string
 	RelayProvider
+	// ImageCacheDir is the node directory of the image cache
+	ImageCacheDir       string
+	ImageCachePopulator *imagecache.Populator
 
-	migrationControlBlocks map[types.UID]migrationControlBlock
-	mutex                  sync.Mutex
+	migrationControlBlocks  map[types.UID]migrationControlBlock
+	volumeMoveControlBlocks map[types.UID]volumeMoveControlBlock
+	mutex                   sync.Mutex
 }
 
 // +kubebuilder:rbac:groups=virt.virtink.smartx.com,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
@@ -119,6 +131,30 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 		return r.reconcileDeletingVM(ctx, vm, vmPod)
 	}
 
+	switch vm.Status.Phase {
+	case virtv1alpha1.VirtualMachineScheduling, virtv1alpha1.VirtualMachineScheduled, virtv1alpha1.VirtualMachineRunning:
+		if vm.Status.NodeName == r.NodeName {
+			if err := r.reconcileImageCache(ctx, vm, vmPod); err != nil {
+				return fmt.Errorf("reconcile image cache: %s", err)
+			}
+		}
+
+		if vm.Status.Migration != nil && vm.Status.Migration.TargetNodeName == r.NodeName && vm.Status.Migration.TargetVMPodName != "" {
+			var targetVMPod corev1.Pod
+			targetVMPodKey := types.NamespacedName{
+				Name:      vm.Status.Migration.TargetVMPodName,
+				Namespace: vm.Namespace,
+			}
+			if err := r.Get(ctx, targetVMPodKey, &targetVMPod); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return fmt.Errorf("get target VM Pod: %s", err)
+				}
+			} else if err := r.reconcileImageCache(ctx, vm, &targetVMPod); err != nil {
+				return fmt.Errorf("reconcile image cache of target VM Pod: %s", err)
+			}
+		}
+	}
+
 	switch vm.Status.Phase {
 	case virtv1alpha1.VirtualMachineScheduled:
 		if err := r.mountHotplugVolumes(ctx, vm, "", ""); err != nil {
@@ -145,6 +181,12 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 				return err
 			}
 
+			if err := validateHostDiskPaths(vm); err != nil {
+				r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedCreate", "Refused to create VM: %s", err)
+				vm.Status.Phase = virtv1alpha1.VirtualMachineFailed
+				return nil
+			}
+
 			if len(vmConfig.Devices) > 0 || len(vmConfig.Vdpa) > 0 {
 				cloudHypervisorPID, err := pid.GetPIDBySocket(filepath.Join(getVMDataDirPath(vm), "ch.sock"))
 				if err != nil {
@@ -245,6 +287,38 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 				if err := r.reconcileHotplugVolumes(ctx, vm, vmInfo); err != nil {
 					return err
 				}
+
+				if err := r.reconcileVolumeCapacity(ctx, vm); err != nil {
+					return err
+				}
+
+				if vm.Spec.Instance.CPU.Realtime != nil {
+					if err := r.setVCPURealtime(ctx, vm); err != nil {
+						return fmt.Errorf("set vCPU realtime: %s", err)
+					}
+				}
+
+				if vm.Spec.Instance.CPU.IsolateEmulatorThread {
+					if err := r.isolateEmulatorThread(ctx, vm, vmInfo); err != nil {
+						return fmt.Errorf("isolate emulator thread: %s", err)
+					}
+				}
+
+				if err := r.reconcileVolumeMove(ctx, vm, vmInfo); err != nil {
+					return err
+				}
+
+				if vm.Spec.Instance.Pvpanic != nil {
+					if err := r.reconcileGuestPanic(ctx, vm); err != nil {
+						return fmt.Errorf("reconcile guest panic: %s", err)
+					}
+				}
+
+				if vm.Spec.Instance.Watchdog != nil {
+					if err := r.reconcileWatchdog(ctx, vm); err != nil {
+						return fmt.Errorf("reconcile watchdog: %s", err)
+					}
+				}
 			} else {
 				vm.Status.Phase = virtv1alpha1.VirtualMachineSucceeded
 			}
@@ -427,6 +501,136 @@ func (r *VMReconciler) reconcile(ctx context.Context, vm *virtv1alpha1.VirtualMa
 	return nil
 }
 
+// reconcileImageCache serves the kernel and disks of containerDisk volumes of the VM Pod from the image cache. Init
+// containers run user images, so they have no access to the cache. Their images are looked up in the cache while
+// they are running, and they skip copying cached images. Images copied on cache misses are added to the cache in the
+// background once init containers complete, while the VM Pod uses the copies.
+func (r *VMReconciler) reconcileImageCache(ctx context.Context, vm *virtv1alpha1.VirtualMachine, vmPod *corev1.Pod) error {
+	if vm.Spec.Instance.Kernel != nil {
+		if err := r.reconcileImageCacheEntry(ctx, vmPod, "init-kernel", "virtink-kernel", "kernel", []string{"vmlinux", "initrd"}); err != nil {
+			return fmt.Errorf("kernel: %s", err)
+		}
+	}
+
+	for _, volume := range vm.Spec.Volumes {
+		if volume.ContainerDisk == nil {
+			continue
+		}
+		// pmem devices map private raw disks into the guest
+		isPmem := false
+		for _, pmem := range vm.Spec.Instance.PmemDevices {
+			if pmem.Name == volume.Name {
+				isPmem = true
+			}
+		}
+		if isPmem {
+			continue
+		}
+		if err := r.reconcileImageCacheEntry(ctx, vmPod, "init-volume-"+volume.Name, volume.Name, "disk", []string{"disk.qcow2"}); err != nil {
+			return fmt.Errorf("disk of volume %q: %s", volume.Name, err)
+		}
+	}
+	return nil
+}
+
+// reconcileImageCacheEntry looks the image of an init container up in the image cache while the init container is
+// running, and adds the files it copies into its volume to the cache once it completes, of which only the first one
+// is required.
+func (r *VMReconciler) reconcileImageCacheEntry(ctx context.Context, vmPod *corev1.Pod, initContainerName string, volume string, kind string, fileNames []string) error {
+	var containerStatus *corev1.ContainerStatus
+	for i := range vmPod.Status.InitContainerStatuses {
+		if vmPod.Status.InitContainerStatuses[i].Name == initContainerName {
+			containerStatus = &vmPod.Status.InitContainerStatuses[i]
+		}
+	}
+	if containerStatus == nil || containerStatus.ImageID == "" {
+		return nil
+	}
+
+	dir := getVMPodEmptyDirPath(vmPod.UID, volume)
+	if _, err := os.Stat(filepath.Join(dir, imagecache.EntryFileName)); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	switch {
+	case containerStatus.State.Running != nil:
+		missFilePath := filepath.Join(dir, imagecache.MissFileName)
+		if _, err := os.Stat(missFilePath); err == nil {
+			return nil
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		entryPath, err := imagecache.Lookup(r.ImageCacheDir, kind, containerStatus.ImageID, vmPod.UID)
+		if err != nil {
+			return fmt.Errorf("look up image: %s", err)
+		}
+		if entryPath == "" {
+			return os.WriteFile(missFilePath, nil, 0644)
+		}
+		ctrl.LoggerFrom(ctx).Info("Found image in cache", "image", containerStatus.Image, "entry", entryPath)
+		return imagecache.WriteEntryFile(dir, entryPath)
+	case containerStatus.State.Terminated != nil && containerStatus.State.Terminated.ExitCode == 0:
+		path := filepath.Join(dir, fileNames[0])
+		if kind == "disk" {
+			// disks of images based on released versions of virtink-container-disk-base are private raw disks
+			isQCOW2, err := imagecache.IsQCOW2(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !isQCOW2 {
+				return nil
+			}
+		} else if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		r.ImageCachePopulator.Populate(imagecache.PopulateRequest{
+			Kind:      kind,
+			ImageID:   containerStatus.ImageID,
+			Dir:       dir,
+			FileNames: fileNames,
+			PodUID:    vmPod.UID,
+		})
+	}
+	return nil
+}
+
+// hostRootPath is the root directory of the node, which is accessible in the host PID namespace.
+var hostRootPath = "/proc/1/root"
+
+// validateHostDiskPaths checks that paths of hostDisk volumes contain no symlinks on the node. The webhook only checks
+// paths lexically against the allowed directories, while kubelet follows symlinks when mounting the images, which
+// could lead outside the allowed directories.
+func validateHostDiskPaths(vm *virtv1alpha1.VirtualMachine) error {
+	for _, volume := range vm.Spec.Volumes {
+		if volume.HostDisk == nil {
+			continue
+		}
+		path := filepath.Join(hostRootPath, volume.HostDisk.Path)
+		resolvedPath, err := securejoin.SecureJoin(hostRootPath, volume.HostDisk.Path)
+		if err != nil {
+			return fmt.Errorf("resolve path of volume %q: %s", volume.Name, err)
+		}
+		if resolvedPath != path {
+			return fmt.Errorf("path of volume %q contains symlinks", volume.Name)
+		}
+	}
+	return nil
+}
+
+func getVMPodEmptyDirPath(vmPodUID types.UID, volume string) string {
+	return filepath.Join("var/lib/kubelet/pods", string(vmPodUID), "volumes/kubernetes.io~empty-dir", volume)
+}
+
 func (r *VMReconciler) getCloudHypervisorClient(vm *virtv1alpha1.VirtualMachine) *cloudhypervisor.Client {
 	return cloudhypervisor.NewClient(filepath.Join(getVMDataDirPath(vm), "ch.sock"))
 }
@@ -471,11 +675,7 @@ func (r *VMReconciler) mountHotplugVolumes(ctx context.Context, vm *virtv1alpha1
 		return err
 	}
 
-	for _, volume := range vm.Spec.Volumes {
-		if !volume.IsHotpluggable() {
-			continue
-		}
-
+	for _, volume := range getHotplugVolumes(vm) {
 		var volumeStatus *virtv1alpha1.VolumeStatus
 		for i := range vm.Status.VolumeStatus {
 			if vm.Status.VolumeStatus[i].Name == volume.Name {
@@ -496,11 +696,16 @@ func (r *VMReconciler) mountHotplugVolumes(ctx context.Context, vm *virtv1alpha1
 				return err
 			}
 			if isBlock {
-				if err := r.mountBlockVolume(ctx, vm, volume.Name, vmPodUID, volumePodUID, record); err != nil {
+				if err := r.mountBlockVolume(ctx, vm, volume.Name, volume.Name, vmPodUID, volumePodUID, record); err != nil {
 					return err
 				}
 			} else {
-				if err := r.mountFileSystemVolume(ctx, vm, volume.Name, vmPodUID, volumePodUID, record); err != nil {
+				if isVolumeMoveTarget(vm, volume.Name) {
+					if err := r.createVolumeMoveTargetDiskImage(ctx, vm, vmPodUID, volumePodUID); err != nil {
+						return err
+					}
+				}
+				if err := r.mountFileSystemVolume(ctx, vm, volume.Name, volume.Name, vmPodUID, volumePodUID, record); err != nil {
 					return err
 				}
 			}
@@ -513,7 +718,20 @@ func (r *VMReconciler) mountHotplugVolumes(ctx context.Context, vm *virtv1alpha1
 	return nil
 }
 
-func (r *VMReconciler) mountBlockVolume(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume string, vmPodUID types.UID, volumePodUID types.UID, record *vmMountRecord) error {
+func getHotplugVolumes(vm *virtv1alpha1.VirtualMachine) []virtv1alpha1.Volume {
+	var hotplugVolumes []virtv1alpha1.Volume
+	for _, volume := range vm.Spec.Volumes {
+		if volume.IsHotpluggable() {
+			hotplugVolumes = append(hotplugVolumes, volume)
+		}
+	}
+	if vm.Status.VolumeMove != nil && vm.Status.VolumeMove.TargetVolume.Name != "" {
+		hotplugVolumes = append(hotplugVolumes, vm.Status.VolumeMove.TargetVolume)
+	}
+	return hotplugVolumes
+}
+
+func (r *VMReconciler) mountBlockVolume(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume string, sourceVolume string, vmPodUID types.UID, volumePodUID types.UID, record *vmMountRecord) error {
 	target := filepath.Join("/var/lib/kubelet/pods", string(vmPodUID), "volumes/kubernetes.io~empty-dir/hotplug-volumes/", volume)
 	mounted := true
 	if _, err := os.Stat(target); err != nil {
@@ -523,7 +741,7 @@ func (r *VMReconciler) mountBlockVolume(ctx context.Context, vm *virtv1alpha1.Vi
 		mounted = false
 	}
 
-	source := filepath.Join("/var/lib/kubelet/pods", string(volumePodUID), "volumes/kubernetes.io~empty-dir/hotplug/", volume)
+	source := filepath.Join("/var/lib/kubelet/pods", string(volumePodUID), "volumes/kubernetes.io~empty-dir/hotplug/", sourceVolume)
 	major, minor, _, err := getBlockFileMajorMinor(source)
 	if err != nil {
 		return err
@@ -556,7 +774,7 @@ func (r *VMReconciler) mountBlockVolume(ctx context.Context, vm *virtv1alpha1.Vi
 	return nil
 }
 
-func (r *VMReconciler) mountFileSystemVolume(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume string, vmPodUID types.UID, volumePodUID types.UID, record *vmMountRecord) error {
+func (r *VMReconciler) mountFileSystemVolume(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume string, sourceVolume string, vmPodUID types.UID, volumePodUID types.UID, record *vmMountRecord) error {
 	target := fmt.Sprintf("/var/lib/kubelet/pods/%s/volumes/kubernetes.io~empty-dir/hotplug-volumes/%s.img", vmPodUID, volume)
 	mounted, err := isMounted(target)
 	if err != nil {
@@ -566,7 +784,7 @@ func (r *VMReconciler) mountFileSystemVolume(ctx context.Context, vm *virtv1alph
 		return nil
 	}
 
-	source, err := getHotplugVolumeSourcePathOnHost(volume, string(volumePodUID))
+	source, err := getHotplugVolumeSourcePathOnHost(sourceVolume, string(volumePodUID))
 	if err != nil {
 		return err
 	}
@@ -732,20 +950,12 @@ func (r *VMReconciler) addHotplugVolumesToVM(ctx context.Context, vm *virtv1alph
 		switch volumeStatus.Phase {
 		case virtv1alpha1.VolumeMountedToPod:
 			if _, ok := vmDisksMap[volumeStatus.Name]; !ok {
-				diskConfig := &cloudhypervisor.DiskConfig{
-					Id: volumeStatus.Name,
-				}
-
 				isBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, volume)
 				if err != nil {
 					return err
 				}
 
-				if isBlock {
-					diskConfig.Path = filepath.Join("/hotplug-volumes", volumeStatus.Name)
-				} else {
-					diskConfig.Path = filepath.Join("/hotplug-volumes", fmt.Sprintf("%s.img", volumeStatus.Name))
-				}
+				diskConfig := buildHotplugDiskConfig(vm, volumeStatus.Name, isBlock)
 				if _, err := r.getCloudHypervisorClient(vm).VmAddDisk(ctx, diskConfig); err != nil {
 					return fmt.Errorf("add disk: %s", err)
 				}
@@ -774,6 +984,118 @@ func (r *VMReconciler) removeHotplugVolumesFromVM(ctx context.Context, vm *virtv
 	return nil
 }
 
+// reconcileVolumeCapacity grows the disk images of PVC volumes whose PVCs have been expanded, and reports the new
+// capacity. Cloud Hypervisor v42 has no API to resize disks, and replugging a disk would pull it from under the guest,
+// so the new capacity is only visible to the guest after the VM is restarted or the disk is replugged by the user.
+func (r *VMReconciler) reconcileVolumeCapacity(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	for _, volume := range vm.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil && volume.DataVolume == nil {
+			continue
+		}
+
+		var volumeStatus *virtv1alpha1.VolumeStatus
+		for i := range vm.Status.VolumeStatus {
+			if vm.Status.VolumeStatus[i].Name == volume.Name {
+				volumeStatus = &vm.Status.VolumeStatus[i]
+			}
+		}
+		if volumeStatus == nil {
+			if volume.IsHotpluggable() {
+				continue
+			}
+			vm.Status.VolumeStatus = append(vm.Status.VolumeStatus, virtv1alpha1.VolumeStatus{
+				Name:  volume.Name,
+				Phase: virtv1alpha1.VolumeReady,
+			})
+			volumeStatus = &vm.Status.VolumeStatus[len(vm.Status.VolumeStatus)-1]
+		}
+
+		if volumeStatus.Phase != virtv1alpha1.VolumeReady {
+			continue
+		}
+
+		capacity, err := volumeutil.GetCapacity(ctx, r.Client, vm.Namespace, volume)
+		if err != nil {
+			return err
+		}
+		if capacity == nil {
+			continue
+		}
+		if volumeStatus.Capacity == nil {
+			volumeStatus.Capacity = capacity
+			continue
+		}
+		if capacity.Cmp(*volumeStatus.Capacity) <= 0 {
+			continue
+		}
+
+		isBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, volume)
+		if err != nil {
+			return err
+		}
+		if !isBlock {
+			diskImagePath, err := getVolumeDiskImagePathOnHost(vm, &volume)
+			if err != nil {
+				return err
+			}
+			if err := growDiskImage(diskImagePath, capacity.Value()); err != nil {
+				return fmt.Errorf("grow disk image: %s", err)
+			}
+		}
+		volumeStatus.Capacity = capacity
+		r.Recorder.Eventf(vm, corev1.EventTypeNormal, "ResizedVolume", "Resized volume %s to %s, which is visible to guest after VM restart", volume.Name, capacity.String())
+	}
+	return nil
+}
+
+func getVolumeDiskImagePathOnHost(vm *virtv1alpha1.VirtualMachine, volume *virtv1alpha1.Volume) (string, error) {
+	if volume.IsHotpluggable() {
+		return getHotplugVolumePathOnHost(vm.Status.VMPodUID, volume.Name, false), nil
+	}
+
+	// Volumes attached on VM start are only mounted in the VM Pod, so they are reached through the root of Cloud
+	// Hypervisor.
+	cloudHypervisorPID, err := pid.GetPIDBySocket(filepath.Join(getVMDataDirPath(vm), "ch.sock"))
+	if err != nil {
+		return "", fmt.Errorf("get cloud-hypervisor process pid: %s", err)
+	}
+	return filepath.Join("/proc", strconv.Itoa(cloudHypervisorPID), "root/mnt", volume.Name, "disk.img"), nil
+}
+
+// growDiskImage extends the sparse disk image at path to size bytes. It's a no-op if the image is not smaller, so
+// that it's safe to retry.
+func growDiskImage(path string, size int64) error {
+	currentSize, err := volumeutil.GetDiskSize(path)
+	if err != nil {
+		return err
+	}
+	if currentSize >= size {
+		return nil
+	}
+	return os.Truncate(path, size)
+}
+
+func buildHotplugDiskConfig(vm *virtv1alpha1.VirtualMachine, volume string, isBlock bool) *cloudhypervisor.DiskConfig {
+	diskConfig := &cloudhypervisor.DiskConfig{
+		Id:     volume,
+		Path:   getHotplugVolumeDiskPath(volume, isBlock),
+		Direct: true,
+	}
+	for _, disk := range vm.Spec.Instance.Disks {
+		if disk.Name == volume {
+			diskConfig.Direct = disk.Cache != virtv1alpha1.DiskCacheWriteback
+			diskConfig.Serial = disk.GetSerial()
+			if disk.IOLimits != nil {
+				diskConfig.RateLimiterConfig = cloudhypervisor.NewDiskRateLimiterConfig(disk.IOLimits)
+			}
+			diskConfig.RateLimitGroup = disk.IOLimitGroup
+			diskConfig.NumQueues = disk.NumQueues
+			diskConfig.QueueSize = disk.QueueSize
+		}
+	}
+	return diskConfig
+}
+
 func getHotplugVolumeSourcePathOnHost(volume string, volumePodUID string) (string, error) {
 	pid, err := pid.GetPIDBySocket(fmt.Sprintf("/var/lib/kubelet/pods/%s/volumes/kubernetes.io~empty-dir/hotplug/hp.sock", volumePodUID))
 	if err != nil {
@@ -880,6 +1202,236 @@ func getMountInfos(pid int, filter mountinfo.FilterFunc) ([]*mountinfo.Info, err
 	return mountinfo.GetMountsFromReader(f, filter)
 }
 
+func (r *VMReconciler) setVCPURealtime(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	cloudHypervisorPID, err := pid.GetPIDBySocket(filepath.Join(getVMDataDirPath(vm), "ch.sock"))
+	if err != nil {
+		return fmt.Errorf("get cloud-hypervisor process pid: %s", err)
+	}
+	vcpuTIDs, _, err := pid.GetThreadIDs(cloudHypervisorPID)
+	if err != nil {
+		return err
+	}
+	return setThreadsRealtime(vcpuTIDs, uint32(vm.Spec.Instance.CPU.Realtime.Priority))
+}
+
+// setThreadsRealtime sets the threads to SCHED_FIFO with the priority, skipping threads already set
+func setThreadsRealtime(tids []int, priority uint32) error {
+	for _, tid := range tids {
+		attr, err := unix.SchedGetAttr(tid, 0)
+		if err != nil {
+			return fmt.Errorf("get scheduling attributes of thread %d: %s", tid, err)
+		}
+		if attr.Policy == unix.SCHED_FIFO && attr.Priority == priority {
+			continue
+		}
+
+		if err := unix.SchedSetAttr(tid, &unix.SchedAttr{
+			Size:     unix.SizeofSchedAttr,
+			Policy:   unix.SCHED_FIFO,
+			Priority: priority,
+		}, 0); err != nil {
+			return fmt.Errorf("set scheduling attributes of thread %d: %s", tid, err)
+		}
+	}
+	return nil
+}
+
+func (r *VMReconciler) isolateEmulatorThread(ctx context.Context, vm *virtv1alpha1.VirtualMachine, vmInfo *cloudhypervisor.VmInfo) error {
+	cgroupManager, err := cgroup.NewManager(ctx, vm)
+	if err != nil {
+		return err
+	}
+	if cgroupManager == nil {
+		return nil
+	}
+
+	cpuSet, err := cgroupManager.GetCPUSet()
+	if err != nil {
+		return fmt.Errorf("get CPU set: %s", err)
+	}
+	for _, affinity := range vmInfo.Config.Cpus.Affinity {
+		for _, cpu := range affinity.HostCpus {
+			delete(cpuSet, cpu)
+		}
+	}
+	if len(cpuSet) == 0 {
+		return fmt.Errorf("no CPU left for emulator thread")
+	}
+	return cgroup.SetEmulatorThreadAffinity(cgroupManager, cpuSet)
+}
+
+func (r *VMReconciler) reconcileGuestPanic(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	eventsFile, offset, err := openVMDataFile(vm, "ch-events.json")
+	if err != nil {
+		return fmt.Errorf("open cloud-hypervisor events file: %s", err)
+	}
+	if eventsFile == nil {
+		return nil
+	}
+	defer eventsFile.Close()
+
+	panicked := false
+	decoder := json.NewDecoder(eventsFile)
+	for {
+		var event cloudhypervisor.Event
+		// the last event may be partially written, which is left to the next reconciliation
+		if err := decoder.Decode(&event); err != nil {
+			break
+		}
+		if event.Source == "guest" && event.Event == "panic" {
+			panicked = true
+		}
+	}
+
+	if panicked {
+		r.Recorder.Eventf(vm, corev1.EventTypeWarning, "GuestPanicked", "Guest panicked")
+		meta.SetStatusCondition(&vm.Status.Conditions, metav1.Condition{
+			Type:    string(virtv1alpha1.VirtualMachineGuestPanicked),
+			Status:  metav1.ConditionTrue,
+			Reason:  "GuestPanicked",
+			Message: fmt.Sprintf("guest panicked, action %s is taken", vm.Spec.Instance.Pvpanic.Action),
+		})
+
+		switch vm.Spec.Instance.Pvpanic.Action {
+		case virtv1alpha1.GuestPanicActionReset:
+			if err := r.getCloudHypervisorClient(vm).VmReboot(ctx); err != nil {
+				r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedReset", "Failed to reset VM")
+				return fmt.Errorf("reset VM: %s", err)
+			}
+			r.Recorder.Eventf(vm, corev1.EventTypeNormal, "Reset", "Reset VM")
+		case virtv1alpha1.GuestPanicActionPowerOff:
+			if err := r.powerOffFailedGuest(ctx, vm); err != nil {
+				return err
+			}
+		default:
+			// ignored
+		}
+	}
+
+	// the offset is saved after the action is taken, so that a failed action is retried
+	return saveVMDataFileOffset(vm, "ch-events.json", offset, offset+decoder.InputOffset())
+}
+
+// watchdogExpiredLogMessage is logged by Cloud Hypervisor v42 in virtio-devices/src/watchdog.rs, by
+// error!("Watchdog triggered: {} seconds since last ping", gap), right before it resets the VM.
+const watchdogExpiredLogMessage = "Watchdog triggered: "
+
+// reconcileWatchdog detects watchdog expiry from the log of Cloud Hypervisor, which resets the VM by itself and
+// reports the expiry nowhere else.
+func (r *VMReconciler) reconcileWatchdog(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	logFile, offset, err := openVMDataFile(vm, "ch.log")
+	if err != nil {
+		return fmt.Errorf("open cloud-hypervisor log file: %s", err)
+	}
+	if logFile == nil {
+		return nil
+	}
+	defer logFile.Close()
+
+	expired := false
+	var n int64
+	reader := bufio.NewReader(logFile)
+	for {
+		line, err := reader.ReadString('\n')
+		// the last line may be partially written, which is left to the next reconciliation
+		if err != nil {
+			break
+		}
+		n += int64(len(line))
+		if strings.Contains(line, watchdogExpiredLogMessage) {
+			expired = true
+		}
+	}
+
+	if expired {
+		r.Recorder.Eventf(vm, corev1.EventTypeWarning, "WatchdogExpired", "Watchdog expired")
+		meta.SetStatusCondition(&vm.Status.Conditions, metav1.Condition{
+			Type:    string(virtv1alpha1.VirtualMachineGuestPanicked),
+			Status:  metav1.ConditionTrue,
+			Reason:  "WatchdogExpired",
+			Message: fmt.Sprintf("watchdog expired, action %s is taken", vm.Spec.Instance.Watchdog.Action),
+		})
+
+		switch vm.Spec.Instance.Watchdog.Action {
+		case virtv1alpha1.WatchdogActionPowerOff:
+			if err := r.powerOffFailedGuest(ctx, vm); err != nil {
+				return err
+			}
+		default:
+			// already reset by Cloud Hypervisor, even with the None action
+		}
+	}
+
+	return saveVMDataFileOffset(vm, "ch.log", offset, offset+n)
+}
+
+// powerOffFailedGuest powers off a VM whose guest panicked or hung. The VM is considered failed, so that it's rerun
+// according to the run policy.
+func (r *VMReconciler) powerOffFailedGuest(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
+	if err := r.getCloudHypervisorClient(vm).VmShutdown(ctx); err != nil {
+		r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedPowerOff", "Failed to powered off VM")
+		return fmt.Errorf("power off VM: %s", err)
+	}
+	r.Recorder.Eventf(vm, corev1.EventTypeNormal, "PoweredOff", "Powered off VM")
+	vm.Status.Phase = virtv1alpha1.VirtualMachineFailed
+	return nil
+}
+
+// openVMDataFile opens a file appended to by Cloud Hypervisor in the VM data dir, positioned after the content already
+// handled. The offset is kept along with the file to survive daemon restarts. A nil file is returned if the file does
+// not exist yet.
+func openVMDataFile(vm *virtv1alpha1.VirtualMachine, name string) (*os.File, int64, error) {
+	f, err := os.Open(filepath.Join(getVMDataDirPath(vm), name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	var offset int64
+	offsetData, err := os.ReadFile(filepath.Join(getVMDataDirPath(vm), name+".offset"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			f.Close()
+			return nil, 0, fmt.Errorf("read offset: %s", err)
+		}
+	} else {
+		offset, err = strconv.ParseInt(strings.TrimSpace(string(offsetData)), 10, 64)
+		if err != nil {
+			f.Close()
+			return nil, 0, fmt.Errorf("parse offset: %s", err)
+		}
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("seek: %s", err)
+	}
+	return f, offset, nil
+}
+
+// saveVMDataFileOffset saves the offset of a file opened by openVMDataFile after its content is handled, and frees the
+// handled content by punching a hole in the file, so that the file doesn't keep growing on the node. Unlike truncating,
+// punching holes keeps offsets valid and races no writes of Cloud Hypervisor.
+func saveVMDataFileOffset(vm *virtv1alpha1.VirtualMachine, name string, oldOffset int64, newOffset int64) error {
+	if newOffset == oldOffset {
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(getVMDataDirPath(vm), name+".offset"), []byte(strconv.FormatInt(newOffset, 10)), 0644); err != nil {
+		return fmt.Errorf("write %s offset: %s", name, err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(getVMDataDirPath(vm), name), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("open %s: %s", name, err)
+	}
+	defer f.Close()
+	if err := unix.Fallocate(int(f.Fd()), unix.FALLOC_FL_PUNCH_HOLE|unix.FALLOC_FL_KEEP_SIZE, 0, newOffset); err != nil && err != unix.EOPNOTSUPP {
+		return fmt.Errorf("punch hole in %s: %s", name, err)
+	}
+	return nil
+}
+
 func (r *VMReconciler) cleanup(ctx context.Context, vm *virtv1alpha1.VirtualMachine) error {
 	return r.umountAllHotplugVolumes(ctx, vm)
 }
@@ -912,9 +1464,37 @@ func executeCommand(name string, arg ...string) (string, error) {
 
 func (r *VMReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.migrationControlBlocks = map[types.UID]migrationControlBlock{}
+	r.volumeMoveControlBlocks = map[types.UID]volumeMoveControlBlock{}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&virtv1alpha1.VirtualMachine{}).
 		Owns(&corev1.Pod{}).
+		Watches(&corev1.PersistentVolumeClaim{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			if _, ok := obj.(*corev1.PersistentVolumeClaim); !ok {
+				return nil
+			}
+			var vmList virtv1alpha1.VirtualMachineList
+			if err := r.Client.List(context.Background(), &vmList, client.InNamespace(obj.GetNamespace())); err != nil {
+				return nil
+			}
+			requests := []reconcile.Request{}
+			for _, vm := range vmList.Items {
+				if vm.Status.NodeName != r.NodeName {
+					continue
+				}
+				for _, volume := range vm.Spec.Volumes {
+					if volume.PVCName() == obj.GetName() {
+						requests = append(requests, reconcile.Request{
+							NamespacedName: types.NamespacedName{
+								Namespace: vm.Namespace,
+								Name:      vm.Name,
+							},
+						})
+						break
+					}
+				}
+			}
+			return requests
+		})).
 		Complete(r)
 }
 
@@ -0,0 +1,786 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/daemon/imagecache"
+)
+
+// chEvents are events written by the event monitor of Cloud Hypervisor, which pretty-prints each event.
+const chEvents = `{
+  "timestamp": {
+    "secs": 0,
+    "nanos": 28472375
+  },
+  "source": "vmm",
+  "event": "starting",
+  "properties": null
+}
+
+{
+  "timestamp": {
+    "secs": 5,
+    "nanos": 102743924
+  },
+  "source": "vm",
+  "event": "booted",
+  "properties": null
+}
+
+`
+
+const chPanicEvent = `{
+  "timestamp": {
+    "secs": 63,
+    "nanos": 491862611
+  },
+  "source": "guest",
+  "event": "panic",
+  "properties": null
+}
+
+`
+
+// chLog is written by the logger of Cloud Hypervisor, which prefixes messages with the uptime, the thread name, the
+// level and the source location.
+const chLog = "cloud-hypervisor: 3.071219s: <vcpu0> WARN:devices/src/legacy/debug_port.rs:76 -- [Debug I/O port: Kernel code: 0x41] 3.071196 seconds\n"
+
+const chWatchdogLog = "cloud-hypervisor: 94.413208s: <_watchdog1> ERROR:virtio-devices/src/watchdog.rs:127 -- Watchdog triggered: 21 seconds since last ping\n"
+
+type fakeCloudHypervisor struct {
+	mutex    sync.Mutex
+	requests []string
+	failed   bool
+}
+
+// startFakeCloudHypervisor serves the API of Cloud Hypervisor on the socket of vm, recording the paths of requests.
+func startFakeCloudHypervisor(t *testing.T, vm *virtv1alpha1.VirtualMachine, failed bool) *fakeCloudHypervisor {
+	ch := &fakeCloudHypervisor{
+		failed: failed,
+	}
+	l, err := net.Listen("unix", filepath.Join(getVMDataDirPath(vm), "ch.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ch.mutex.Lock()
+			defer ch.mutex.Unlock()
+			ch.requests = append(ch.requests, r.URL.Path)
+			if ch.failed {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	go server.Serve(l)
+	t.Cleanup(func() {
+		server.Close()
+	})
+	return ch
+}
+
+func (ch *fakeCloudHypervisor) Requests() []string {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+	return ch.requests
+}
+
+// chdirTemp changes the working directory to a temporary directory, in which VM data dirs are created relative to.
+func chdirTemp(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+func TestReconcileGuestPanic(t *testing.T) {
+	// events are handled up to the end of the last complete one
+	eventsEnd := func(events string) int64 {
+		return int64(len(strings.TrimRight(events, "\n")))
+	}
+
+	tests := []struct {
+		events       string
+		offset       int64
+		action       virtv1alpha1.GuestPanicAction
+		chFailed     bool
+		noEventsFile bool
+
+		expectedErr       bool
+		expectedRequests  []string
+		expectedPanicked  bool
+		expectedPhase     virtv1alpha1.VirtualMachinePhase
+		expectedNewOffset int64
+	}{{
+		noEventsFile:     true,
+		action:           virtv1alpha1.GuestPanicActionReset,
+		expectedRequests: nil,
+		expectedPhase:    virtv1alpha1.VirtualMachineRunning,
+	}, {
+		events:            chEvents,
+		action:            virtv1alpha1.GuestPanicActionReset,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents),
+	}, {
+		events:            chEvents + chPanicEvent,
+		action:            virtv1alpha1.GuestPanicActionReset,
+		expectedRequests:  []string{"/api/v1/vm.reboot"},
+		expectedPanicked:  true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents + chPanicEvent),
+	}, {
+		events:            chEvents + chPanicEvent,
+		action:            virtv1alpha1.GuestPanicActionPowerOff,
+		expectedRequests:  []string{"/api/v1/vm.shutdown"},
+		expectedPanicked:  true,
+		expectedPhase:     virtv1alpha1.VirtualMachineFailed,
+		expectedNewOffset: eventsEnd(chEvents + chPanicEvent),
+	}, {
+		events:            chEvents + chPanicEvent,
+		action:            virtv1alpha1.GuestPanicActionNone,
+		expectedPanicked:  true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents + chPanicEvent),
+	}, {
+		events:            chEvents + chPanicEvent,
+		offset:            eventsEnd(chEvents + chPanicEvent),
+		action:            virtv1alpha1.GuestPanicActionReset,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents + chPanicEvent),
+	}, {
+		events:            chEvents + chPanicEvent,
+		offset:            eventsEnd(chEvents),
+		action:            virtv1alpha1.GuestPanicActionReset,
+		expectedRequests:  []string{"/api/v1/vm.reboot"},
+		expectedPanicked:  true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents + chPanicEvent),
+	}, {
+		// the failed action is retried in the next reconciliation
+		events:            chEvents + chPanicEvent,
+		action:            virtv1alpha1.GuestPanicActionReset,
+		chFailed:          true,
+		expectedErr:       true,
+		expectedRequests:  []string{"/api/v1/vm.reboot"},
+		expectedPanicked:  true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: 0,
+	}, {
+		// the partially written event is left to the next reconciliation
+		events:            chEvents + chPanicEvent[:20],
+		action:            virtv1alpha1.GuestPanicActionReset,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: eventsEnd(chEvents),
+	}}
+
+	chdirTemp(t)
+	for i, tc := range tests {
+		vm := &virtv1alpha1.VirtualMachine{
+			Spec: virtv1alpha1.VirtualMachineSpec{
+				Instance: virtv1alpha1.Instance{
+					Pvpanic: &virtv1alpha1.Pvpanic{
+						Action: tc.action,
+					},
+				},
+			},
+			Status: virtv1alpha1.VirtualMachineStatus{
+				Phase:    virtv1alpha1.VirtualMachineRunning,
+				VMPodUID: types.UID("pod-" + strconv.Itoa(i)),
+			},
+		}
+		assert.NoError(t, os.MkdirAll(getVMDataDirPath(vm), 0755), "case %d", i)
+		if !tc.noEventsFile {
+			assert.NoError(t, os.WriteFile(filepath.Join(getVMDataDirPath(vm), "ch-events.json"), []byte(tc.events), 0644), "case %d", i)
+		}
+		if tc.offset != 0 {
+			assert.NoError(t, os.WriteFile(filepath.Join(getVMDataDirPath(vm), "ch-events.json.offset"), []byte(strconv.FormatInt(tc.offset, 10)), 0644), "case %d", i)
+		}
+		ch := startFakeCloudHypervisor(t, vm, tc.chFailed)
+
+		r := &VMReconciler{
+			Recorder: record.NewFakeRecorder(10),
+		}
+		err := r.reconcileGuestPanic(context.Background(), vm)
+		if tc.expectedErr {
+			assert.Error(t, err, "case %d", i)
+		} else {
+			assert.NoError(t, err, "case %d", i)
+		}
+		assert.Equal(t, tc.expectedRequests, ch.Requests(), "case %d", i)
+		assert.Equal(t, tc.expectedPanicked, meta.IsStatusConditionTrue(vm.Status.Conditions, string(virtv1alpha1.VirtualMachineGuestPanicked)), "case %d", i)
+		assert.Equal(t, tc.expectedPhase, vm.Status.Phase, "case %d", i)
+
+		if tc.noEventsFile {
+			continue
+		}
+		f, offset, err := openVMDataFile(vm, "ch-events.json")
+		if assert.NoError(t, err, "case %d", i) {
+			assert.Equal(t, tc.expectedNewOffset, offset, "case %d", i)
+			f.Close()
+		}
+	}
+}
+
+func TestReconcileWatchdog(t *testing.T) {
+	tests := []struct {
+		log    string
+		action virtv1alpha1.WatchdogAction
+
+		expectedRequests  []string
+		expectedExpired   bool
+		expectedPhase     virtv1alpha1.VirtualMachinePhase
+		expectedNewOffset int64
+	}{{
+		log:               chLog,
+		action:            virtv1alpha1.WatchdogActionReset,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: int64(len(chLog)),
+	}, {
+		log:               chLog + chWatchdogLog,
+		action:            virtv1alpha1.WatchdogActionReset,
+		expectedExpired:   true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: int64(len(chLog + chWatchdogLog)),
+	}, {
+		log:               chLog + chWatchdogLog,
+		action:            virtv1alpha1.WatchdogActionNone,
+		expectedExpired:   true,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: int64(len(chLog + chWatchdogLog)),
+	}, {
+		log:               chLog + chWatchdogLog,
+		action:            virtv1alpha1.WatchdogActionPowerOff,
+		expectedRequests:  []string{"/api/v1/vm.shutdown"},
+		expectedExpired:   true,
+		expectedPhase:     virtv1alpha1.VirtualMachineFailed,
+		expectedNewOffset: int64(len(chLog + chWatchdogLog)),
+	}, {
+		// the partially written line is left to the next reconciliation
+		log:               chLog + chWatchdogLog[:len(chWatchdogLog)-1],
+		action:            virtv1alpha1.WatchdogActionPowerOff,
+		expectedPhase:     virtv1alpha1.VirtualMachineRunning,
+		expectedNewOffset: int64(len(chLog)),
+	}}
+
+	chdirTemp(t)
+	for i, tc := range tests {
+		vm := &virtv1alpha1.VirtualMachine{
+			Spec: virtv1alpha1.VirtualMachineSpec{
+				Instance: virtv1alpha1.Instance{
+					Watchdog: &virtv1alpha1.Watchdog{
+						Action: tc.action,
+					},
+				},
+			},
+			Status: virtv1alpha1.VirtualMachineStatus{
+				Phase:    virtv1alpha1.VirtualMachineRunning,
+				VMPodUID: types.UID("pod-" + strconv.Itoa(i)),
+			},
+		}
+		assert.NoError(t, os.MkdirAll(getVMDataDirPath(vm), 0755), "case %d", i)
+		assert.NoError(t, os.WriteFile(filepath.Join(getVMDataDirPath(vm), "ch.log"), []byte(tc.log), 0644), "case %d", i)
+		ch := startFakeCloudHypervisor(t, vm, false)
+
+		r := &VMReconciler{
+			Recorder: record.NewFakeRecorder(10),
+		}
+		assert.NoError(t, r.reconcileWatchdog(context.Background(), vm), "case %d", i)
+		assert.Equal(t, tc.expectedRequests, ch.Requests(), "case %d", i)
+		assert.Equal(t, tc.expectedExpired, meta.IsStatusConditionTrue(vm.Status.Conditions, string(virtv1alpha1.VirtualMachineGuestPanicked)), "case %d", i)
+		assert.Equal(t, tc.expectedPhase, vm.Status.Phase, "case %d", i)
+
+		f, offset, err := openVMDataFile(vm, "ch.log")
+		if assert.NoError(t, err, "case %d", i) {
+			assert.Equal(t, tc.expectedNewOffset, offset, "case %d", i)
+			f.Close()
+		}
+	}
+}
+
+func TestSaveVMDataFileOffset(t *testing.T) {
+	chdirTemp(t)
+	vm := &virtv1alpha1.VirtualMachine{
+		Status: virtv1alpha1.VirtualMachineStatus{
+			VMPodUID: "pod-1",
+		},
+	}
+	assert.NoError(t, os.MkdirAll(getVMDataDirPath(vm), 0755))
+
+	f, offset, err := openVMDataFile(vm, "ch.log")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+	assert.Equal(t, int64(0), offset)
+
+	data := make([]byte, 1024*1024)
+	for i := range data {
+		data[i] = 'a'
+	}
+	path := filepath.Join(getVMDataDirPath(vm), "ch.log")
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	defer w.Close()
+	_, err = w.Write(data)
+	assert.NoError(t, err)
+
+	f, offset, err = openVMDataFile(vm, "ch.log")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), offset)
+	f.Close()
+	assert.NoError(t, saveVMDataFileOffset(vm, "ch.log", offset, int64(len(data))))
+
+	// content written after the handled content is kept
+	_, err = w.Write([]byte("b\n"))
+	assert.NoError(t, err)
+
+	f, offset, err = openVMDataFile(vm, "ch.log")
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, int64(len(data)), offset)
+	rest := make([]byte, 16)
+	n, _ := f.Read(rest)
+	assert.Equal(t, "b\n", string(rest[:n]))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)+2), info.Size())
+	// the handled content is freed, unless hole punching is unsupported by the file system of the test
+	assert.Less(t, info.Sys().(*syscall.Stat_t).Blocks*512, int64(len(data)))
+}
+
+func TestReconcileVolumeCapacity(t *testing.T) {
+	var scheme = runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(virtv1alpha1.AddToScheme(scheme))
+
+	volumeMode := corev1.PersistentVolumeBlock
+	pvc := func(name string, capacity string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				VolumeMode: &volumeMode,
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(capacity),
+				},
+			},
+		}
+	}
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+
+	validVM := &virtv1alpha1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-vm",
+			Namespace: "default",
+		},
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Volumes: []virtv1alpha1.Volume{{
+				Name: "root",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "root",
+					},
+				},
+			}, {
+				Name: "data",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					PersistentVolumeClaim: &virtv1alpha1.PersistentVolumeClaimVolumeSource{
+						ClaimName:    "data",
+						Hotpluggable: true,
+					},
+				},
+			}},
+		},
+	}
+
+	tests := []struct {
+		volumeStatus []virtv1alpha1.VolumeStatus
+		pvcs         []*corev1.PersistentVolumeClaim
+
+		expectedVolumeStatus []virtv1alpha1.VolumeStatus
+		expectedEvents       int
+	}{{
+		pvcs: []*corev1.PersistentVolumeClaim{pvc("root", "10Gi"), pvc("data", "10Gi")},
+		expectedVolumeStatus: []virtv1alpha1.VolumeStatus{{
+			Name:     "root",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("10Gi"),
+		}},
+	}, {
+		volumeStatus: []virtv1alpha1.VolumeStatus{{
+			Name:     "root",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("10Gi"),
+		}, {
+			Name:     "data",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("10Gi"),
+		}},
+		pvcs: []*corev1.PersistentVolumeClaim{pvc("root", "20Gi"), pvc("data", "30Gi")},
+		expectedVolumeStatus: []virtv1alpha1.VolumeStatus{{
+			Name:     "root",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("20Gi"),
+		}, {
+			Name:     "data",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("30Gi"),
+		}},
+		expectedEvents: 2,
+	}, {
+		volumeStatus: []virtv1alpha1.VolumeStatus{{
+			Name:     "root",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("20Gi"),
+		}, {
+			Name:  "data",
+			Phase: virtv1alpha1.VolumeMountedToPod,
+		}},
+		pvcs: []*corev1.PersistentVolumeClaim{pvc("root", "20Gi"), pvc("data", "30Gi")},
+		expectedVolumeStatus: []virtv1alpha1.VolumeStatus{{
+			Name:     "root",
+			Phase:    virtv1alpha1.VolumeReady,
+			Capacity: quantity("20Gi"),
+		}, {
+			Name:  "data",
+			Phase: virtv1alpha1.VolumeMountedToPod,
+		}},
+	}}
+
+	for i, tc := range tests {
+		var objs []client.Object
+		for _, pvc := range tc.pvcs {
+			objs = append(objs, pvc.DeepCopy())
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &VMReconciler{
+			Client:   c,
+			Recorder: recorder,
+		}
+
+		vm := validVM.DeepCopy()
+		vm.Status.VolumeStatus = tc.volumeStatus
+		assert.NoError(t, r.reconcileVolumeCapacity(context.Background(), vm), "case %d", i)
+		assert.Equal(t, len(tc.expectedVolumeStatus), len(vm.Status.VolumeStatus), "case %d", i)
+		for j, expected := range tc.expectedVolumeStatus {
+			actual := vm.Status.VolumeStatus[j]
+			assert.Equal(t, expected.Name, actual.Name, "case %d", i)
+			assert.Equal(t, expected.Phase, actual.Phase, "case %d", i)
+			if expected.Capacity == nil {
+				assert.Nil(t, actual.Capacity, "case %d", i)
+			} else if assert.NotNil(t, actual.Capacity, "case %d", i) {
+				assert.Equal(t, 0, expected.Capacity.Cmp(*actual.Capacity), "case %d", i)
+			}
+		}
+		assert.Len(t, recorder.Events, tc.expectedEvents, "case %d", i)
+	}
+}
+
+func TestGrowDiskImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.img")
+	assert.NoError(t, os.WriteFile(path, make([]byte, 1024*1024), 0644))
+
+	assert.NoError(t, growDiskImage(path, 2*1024*1024))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*1024*1024), info.Size())
+
+	assert.NoError(t, growDiskImage(path, 1024*1024))
+	info, err = os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2*1024*1024), info.Size())
+}
+
+func TestReconcileImageCache(t *testing.T) {
+	qcow2Data := append([]byte{'Q', 'F', 'I', 0xfb}, make([]byte, 1020)...)
+	rawData := make([]byte, 1024)
+
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	completed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	failed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}
+
+	vm := &virtv1alpha1.VirtualMachine{
+		Spec: virtv1alpha1.VirtualMachineSpec{
+			Instance: virtv1alpha1.Instance{
+				Kernel: &virtv1alpha1.Kernel{
+					Image: "kernel",
+				},
+				PmemDevices: []virtv1alpha1.PmemDevice{{
+					Name: "pmem",
+				}},
+			},
+			Volumes: []virtv1alpha1.Volume{{
+				Name: "root",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ContainerDisk: &virtv1alpha1.ContainerDiskVolumeSource{
+						Image: "disk",
+					},
+				},
+			}, {
+				Name: "pmem",
+				VolumeSource: virtv1alpha1.VolumeSource{
+					ContainerDisk: &virtv1alpha1.ContainerDiskVolumeSource{
+						Image: "disk",
+					},
+				},
+			}},
+		},
+	}
+
+	tests := []struct {
+		state   corev1.ContainerState
+		imageID string
+		cached  bool
+		files   map[string][]byte
+		// files in the kernel volume, and the disk volume if expectedDiskFiles is nil
+		expectedFiles     []string
+		expectedDiskFiles []string
+	}{{
+		state: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}},
+	}, {
+		state: running,
+	}, {
+		state:         running,
+		imageID:       "uncached",
+		expectedFiles: []string{"image-cache-miss"},
+	}, {
+		state:         running,
+		imageID:       "cached",
+		cached:        true,
+		expectedFiles: []string{"image-cache-entry"},
+	}, {
+		state:         running,
+		imageID:       "uncached",
+		files:         map[string][]byte{"image-cache-miss": nil},
+		expectedFiles: []string{"image-cache-miss"},
+	}, {
+		state:         failed,
+		imageID:       "uncached",
+		files:         map[string][]byte{"vmlinux": qcow2Data, "disk.qcow2": qcow2Data},
+		expectedFiles: []string{"vmlinux", "disk.qcow2"},
+	}, {
+		// disks of images based on released versions of virtink-container-disk-base are private raw disks
+		state:             completed,
+		imageID:           "raw",
+		files:             map[string][]byte{"vmlinux": rawData, "disk.qcow2": rawData, "image-cache-miss": nil},
+		expectedFiles:     []string{"vmlinux", "disk.qcow2", "image-cache-miss", "image-cache-entry"},
+		expectedDiskFiles: []string{"vmlinux", "disk.qcow2", "image-cache-miss"},
+	}, {
+		state:         completed,
+		imageID:       "uncached",
+		files:         map[string][]byte{"vmlinux": qcow2Data, "disk.qcow2": qcow2Data, "image-cache-miss": nil},
+		expectedFiles: []string{"vmlinux", "disk.qcow2", "image-cache-miss", "image-cache-entry"},
+	}}
+
+	chdirTemp(t)
+	cacheDir := t.TempDir()
+	populator := imagecache.NewPopulator(cacheDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go populator.Start(ctx)
+
+	r := &VMReconciler{
+		ImageCacheDir:       cacheDir,
+		ImageCachePopulator: populator,
+	}
+	for i, tc := range tests {
+		vmPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				UID: types.UID("pod-" + strconv.Itoa(i)),
+			},
+		}
+		for _, name := range []string{"init-kernel", "init-volume-root", "init-volume-pmem"} {
+			vmPod.Status.InitContainerStatuses = append(vmPod.Status.InitContainerStatuses, corev1.ContainerStatus{
+				Name:    name,
+				State:   tc.state,
+				ImageID: tc.imageID,
+			})
+		}
+
+		volumes := map[string]string{"virtink-kernel": "kernel", "root": "disk", "pmem": "disk"}
+		for volume, kind := range volumes {
+			dir := getVMPodEmptyDirPath(vmPod.UID, volume)
+			assert.NoError(t, os.MkdirAll(dir, 0755), "case %d", i)
+			for name, data := range tc.files {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644), "case %d", i)
+			}
+			if tc.cached && volume != "pmem" {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, "image"), nil, 0644), "case %d", i)
+				_, err := imagecache.Add(cacheDir, kind, tc.imageID, []string{filepath.Join(dir, "image")}, "other-pod")
+				assert.NoError(t, err, "case %d", i)
+				assert.NoError(t, os.Remove(filepath.Join(dir, "image")), "case %d", i)
+			}
+		}
+
+		assert.NoError(t, r.reconcileImageCache(context.Background(), vm, vmPod), "case %d", i)
+
+		for volume, kind := range volumes {
+			dir := getVMPodEmptyDirPath(vmPod.UID, volume)
+			expectedFiles := tc.expectedFiles
+			switch {
+			case volume == "pmem":
+				// pmem devices are never cached
+				expectedFiles = nil
+				for name := range tc.files {
+					expectedFiles = append(expectedFiles, name)
+				}
+			case volume == "root" && tc.expectedDiskFiles != nil:
+				expectedFiles = tc.expectedDiskFiles
+			}
+			if tc.state.Terminated != nil && slices.Contains(expectedFiles, imagecache.EntryFileName) {
+				// populated in the background
+				assert.Eventually(t, func() bool {
+					_, err := os.Stat(filepath.Join(dir, imagecache.EntryFileName))
+					return err == nil
+				}, 10*time.Second, 10*time.Millisecond, "case %d: %s", i, volume)
+			}
+
+			entries, err := os.ReadDir(dir)
+			assert.NoError(t, err, "case %d", i)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			assert.ElementsMatch(t, expectedFiles, names, "case %d: %s", i, volume)
+
+			if slices.Contains(expectedFiles, imagecache.EntryFileName) {
+				entryPath, err := os.ReadFile(filepath.Join(dir, imagecache.EntryFileName))
+				assert.NoError(t, err, "case %d", i)
+				assert.Equal(t, imagecache.GetEntryPath(cacheDir, kind, tc.imageID), string(entryPath), "case %d: %s", i, volume)
+				assert.FileExists(t, filepath.Join(string(entryPath), "refs", string(vmPod.UID)), "case %d: %s", i, volume)
+			}
+		}
+	}
+}
+
+func TestValidateHostDiskPaths(t *testing.T) {
+	root := t.TempDir()
+	defer func(path string) {
+		hostRootPath = path
+	}(hostRootPath)
+	hostRootPath = root
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "var/lib/images"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "var/lib/images/ubuntu.img"), nil, 0644))
+	assert.NoError(t, os.Symlink("/etc/shadow", filepath.Join(root, "var/lib/images/shadow.img")))
+	assert.NoError(t, os.Symlink("ubuntu.img", filepath.Join(root, "var/lib/images/link.img")))
+	assert.NoError(t, os.Symlink("/etc", filepath.Join(root, "var/lib/images/etc")))
+
+	tests := []struct {
+		path         string
+		expectedFail bool
+	}{{
+		path: "/var/lib/images/ubuntu.img",
+	}, {
+		path: "/var/lib/images/new.img",
+	}, {
+		path:         "/var/lib/images/shadow.img",
+		expectedFail: true,
+	}, {
+		path:         "/var/lib/images/link.img",
+		expectedFail: true,
+	}, {
+		path:         "/var/lib/images/etc/shadow",
+		expectedFail: true,
+	}}
+
+	for i, tc := range tests {
+		vm := &virtv1alpha1.VirtualMachine{
+			Spec: virtv1alpha1.VirtualMachineSpec{
+				Volumes: []virtv1alpha1.Volume{{
+					Name: "root",
+					VolumeSource: virtv1alpha1.VolumeSource{
+						ContainerDisk: &virtv1alpha1.ContainerDiskVolumeSource{
+							Image: "disk",
+						},
+					},
+				}, {
+					Name: "data",
+					VolumeSource: virtv1alpha1.VolumeSource{
+						HostDisk: &virtv1alpha1.HostDiskVolumeSource{
+							Path:     tc.path,
+							NodeName: "node-1",
+						},
+					},
+				}},
+			},
+		}
+		err := validateHostDiskPaths(vm)
+		if tc.expectedFail {
+			assert.Error(t, err, "case %d", i)
+		} else {
+			assert.NoError(t, err, "case %d", i)
+		}
+	}
+}
+
+func TestSetThreadsRealtime(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	assert.NoError(t, cmd.Start())
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	tid := cmd.Process.Pid
+	err := setThreadsRealtime([]int{tid}, 10)
+	if err != nil && strings.Contains(err.Error(), unix.EPERM.Error()) {
+		t.Skip("SCHED_FIFO requires CAP_SYS_NICE")
+	}
+	assert.NoError(t, err)
+	attr, err := unix.SchedGetAttr(tid, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(unix.SCHED_FIFO), attr.Policy)
+	assert.Equal(t, uint32(10), attr.Priority)
+
+	// threads already set are skipped, and the priority is updated otherwise
+	assert.NoError(t, setThreadsRealtime([]int{tid}, 10))
+	assert.NoError(t, setThreadsRealtime([]int{tid}, 20))
+	attr, err = unix.SchedGetAttr(tid, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(20), attr.Priority)
+
+	assert.Error(t, setThreadsRealtime([]int{1 << 30}, 10))
+}
@@ -0,0 +1,336 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"github.com/smartxworks/virtink/pkg/cloudhypervisor"
+	"github.com/smartxworks/virtink/pkg/volumeutil"
+)
+
+const (
+	volumeMoveChunkSize = 4 << 20
+)
+
+type volumeMoveControlBlock struct {
+	Final          bool
+	SyncErrCh      <-chan error
+	SyncCancelFunc context.CancelFunc
+}
+
+func (r *VMReconciler) reconcileVolumeMove(ctx context.Context, vm *virtv1alpha1.VirtualMachine, vmInfo *cloudhypervisor.VmInfo) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	volumeMove := vm.Status.VolumeMove
+	controlBlock, hasControlBlock := r.volumeMoveControlBlocks[vm.UID]
+	if volumeMove == nil {
+		// The volume move was aborted by deleting the VMVM.
+		if hasControlBlock {
+			controlBlock.SyncCancelFunc()
+			delete(r.volumeMoveControlBlocks, vm.UID)
+		}
+		return nil
+	}
+
+	var volume *virtv1alpha1.Volume
+	for i := range vm.Spec.Volumes {
+		if vm.Spec.Volumes[i].Name == volumeMove.VolumeName {
+			volume = &vm.Spec.Volumes[i]
+		}
+	}
+	var targetVolumeStatus *virtv1alpha1.VolumeStatus
+	for i := range vm.Status.VolumeStatus {
+		if vm.Status.VolumeStatus[i].Name == volumeMove.TargetVolume.Name {
+			targetVolumeStatus = &vm.Status.VolumeStatus[i]
+		}
+	}
+
+	switch volumeMove.Phase {
+	case virtv1alpha1.VirtualMachineVolumeMoveAttaching, virtv1alpha1.VirtualMachineVolumeMoveCopying:
+		if volume == nil {
+			r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedMoveVolume", "Volume %s not found", volumeMove.VolumeName)
+			volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveFailed
+			return nil
+		}
+		if targetVolumeStatus == nil || targetVolumeStatus.Phase != virtv1alpha1.VolumeMountedToPod {
+			return nil
+		}
+
+		if !hasControlBlock {
+			source, target, err := r.getVolumeMoveDiskPaths(ctx, vm, volume)
+			if err != nil {
+				return err
+			}
+			r.startVolumeMoveSync(vm, source, target, false)
+			if volumeMove.Phase == virtv1alpha1.VirtualMachineVolumeMoveAttaching {
+				r.Recorder.Eventf(vm, corev1.EventTypeNormal, "CopyingVolume", "Copying volume %s to %s", volume.Name, volumeMove.TargetVolume.PVCName())
+			}
+			volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveCopying
+			return nil
+		}
+
+		select {
+		case err := <-controlBlock.SyncErrCh:
+			delete(r.volumeMoveControlBlocks, vm.UID)
+			if err != nil {
+				r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedMoveVolume", "Failed to copy volume %s: %s", volume.Name, err)
+				volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveFailed
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		// Cloud Hypervisor neither tracks dirty blocks of disks nor swaps their backing files in place, so the
+		// copy above is only a pre-copy. The source disk is unplugged to stop guest writes, then synced a final
+		// time by comparing every chunk and replugged with the target volume.
+		if err := r.getCloudHypervisorClient(vm).VmRemoveDevice(ctx, &cloudhypervisor.VmRemoveDevice{Id: volume.Name}); err != nil {
+			return fmt.Errorf("remove disk from VM: %s", err)
+		}
+		r.Recorder.Eventf(vm, corev1.EventTypeNormal, "RemoveDiskFromVM", "Remove disk %s from VM", volume.Name)
+		volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveSwitching
+	case virtv1alpha1.VirtualMachineVolumeMoveSwitching:
+		if volume == nil || targetVolumeStatus == nil {
+			volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveFailed
+			return nil
+		}
+		for _, disk := range vmInfo.Config.Disks {
+			if disk.Id == volume.Name {
+				return nil
+			}
+		}
+
+		source, target, err := r.getVolumeMoveDiskPaths(ctx, vm, volume)
+		if err != nil {
+			return err
+		}
+
+		if !hasControlBlock || !controlBlock.Final {
+			r.startVolumeMoveSync(vm, source, target, true)
+			return nil
+		}
+
+		select {
+		case err := <-controlBlock.SyncErrCh:
+			delete(r.volumeMoveControlBlocks, vm.UID)
+			if err != nil {
+				r.Recorder.Eventf(vm, corev1.EventTypeWarning, "FailedMoveVolume", "Failed to sync volume %s: %s", volume.Name, err)
+				if _, err := r.getCloudHypervisorClient(vm).VmAddDisk(ctx, buildHotplugDiskConfig(vm, volume.Name, isBlockFile(source))); err != nil {
+					return fmt.Errorf("add disk: %s", err)
+				}
+				volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveFailed
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		if err := r.switchVolumeMoveTarget(ctx, vm, volume); err != nil {
+			return err
+		}
+		for i := range vm.Status.VolumeStatus {
+			if vm.Status.VolumeStatus[i].Name == volume.Name {
+				vm.Status.VolumeStatus[i].Capacity = nil
+			}
+		}
+
+		if _, err := r.getCloudHypervisorClient(vm).VmAddDisk(ctx, buildHotplugDiskConfig(vm, volume.Name, isBlockFile(target))); err != nil {
+			return fmt.Errorf("add disk: %s", err)
+		}
+		r.Recorder.Eventf(vm, corev1.EventTypeNormal, "AddDiskToVM", "Added disk %s to VM", volume.Name)
+		r.Recorder.Eventf(vm, corev1.EventTypeNormal, "MovedVolume", "Moved volume %s to %s", volume.Name, volumeMove.TargetVolume.PVCName())
+		volumeMove.Phase = virtv1alpha1.VirtualMachineVolumeMoveSwitched
+	default:
+		if hasControlBlock {
+			controlBlock.SyncCancelFunc()
+			delete(r.volumeMoveControlBlocks, vm.UID)
+		}
+	}
+	return nil
+}
+
+func (r *VMReconciler) startVolumeMoveSync(vm *virtv1alpha1.VirtualMachine, source string, target string, final bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	syncErrCh := make(chan error, 1)
+	go func() {
+		syncErrCh <- syncDisk(ctx, source, target)
+	}()
+	r.volumeMoveControlBlocks[vm.UID] = volumeMoveControlBlock{
+		Final:          final,
+		SyncErrCh:      syncErrCh,
+		SyncCancelFunc: cancel,
+	}
+}
+
+// switchVolumeMoveTarget replaces the mount of the source volume in the VM pod with the target volume, so that
+// the disk keeps its path and the VM config stays valid across restarts and live migrations.
+func (r *VMReconciler) switchVolumeMoveTarget(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume *virtv1alpha1.Volume) error {
+	record, err := getVMMountRecord(vm.UID)
+	if err != nil {
+		return err
+	}
+
+	newRecord := &vmMountRecord{}
+	for _, volumeRecord := range record.Volumes {
+		if volumeRecord.Volume != volume.Name {
+			newRecord.Volumes = append(newRecord.Volumes, volumeRecord)
+			continue
+		}
+		if err := r.umountHotplugVolume(ctx, vm, &volumeRecord); err != nil {
+			return err
+		}
+	}
+	if err := writeVMMountRecord(vm.UID, newRecord); err != nil {
+		return err
+	}
+
+	var targetVolumePodUID types.UID
+	for _, volumeStatus := range vm.Status.VolumeStatus {
+		if volumeStatus.Name == vm.Status.VolumeMove.TargetVolume.Name && volumeStatus.HotplugVolume != nil {
+			targetVolumePodUID = volumeStatus.HotplugVolume.VolumePodUID
+		}
+	}
+
+	targetVolume := vm.Status.VolumeMove.TargetVolume
+	isBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, targetVolume)
+	if err != nil {
+		return err
+	}
+	if isBlock {
+		return r.mountBlockVolume(ctx, vm, volume.Name, targetVolume.Name, vm.Status.VMPodUID, targetVolumePodUID, newRecord)
+	}
+	return r.mountFileSystemVolume(ctx, vm, volume.Name, targetVolume.Name, vm.Status.VMPodUID, targetVolumePodUID, newRecord)
+}
+
+func (r *VMReconciler) getVolumeMoveDiskPaths(ctx context.Context, vm *virtv1alpha1.VirtualMachine, volume *virtv1alpha1.Volume) (string, string, error) {
+	isBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, *volume)
+	if err != nil {
+		return "", "", err
+	}
+	source := getHotplugVolumePathOnHost(vm.Status.VMPodUID, volume.Name, isBlock)
+
+	targetVolume := vm.Status.VolumeMove.TargetVolume
+	isTargetBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, targetVolume)
+	if err != nil {
+		return "", "", err
+	}
+	target := getHotplugVolumePathOnHost(vm.Status.VMPodUID, targetVolume.Name, isTargetBlock)
+	return source, target, nil
+}
+
+func (r *VMReconciler) createVolumeMoveTargetDiskImage(ctx context.Context, vm *virtv1alpha1.VirtualMachine, vmPodUID types.UID, volumePodUID types.UID) error {
+	targetVolume := vm.Status.VolumeMove.TargetVolume
+	targetPath, err := getHotplugVolumeSourcePathOnHost(targetVolume.Name, string(volumePodUID))
+	if err != nil {
+		return err
+	}
+	diskImagePath := filepath.Join(targetPath, "disk.img")
+	if _, err := os.Stat(diskImagePath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	var volume *virtv1alpha1.Volume
+	for i := range vm.Spec.Volumes {
+		if vm.Spec.Volumes[i].Name == vm.Status.VolumeMove.VolumeName {
+			volume = &vm.Spec.Volumes[i]
+		}
+	}
+	if volume == nil {
+		return fmt.Errorf("volume %s not found", vm.Status.VolumeMove.VolumeName)
+	}
+	isBlock, err := volumeutil.IsBlock(ctx, r.Client, vm.Namespace, *volume)
+	if err != nil {
+		return err
+	}
+	size, err := volumeutil.GetDiskSize(getHotplugVolumePathOnHost(vmPodUID, volume.Name, isBlock))
+	if err != nil {
+		return fmt.Errorf("get source disk size: %s", err)
+	}
+
+	f, err := os.Create(diskImagePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		return fmt.Errorf("create target disk image: %s", err)
+	}
+	return nil
+}
+
+func isVolumeMoveTarget(vm *virtv1alpha1.VirtualMachine, volume string) bool {
+	return vm.Status.VolumeMove != nil && vm.Status.VolumeMove.TargetVolume.Name == volume
+}
+
+func getHotplugVolumePathOnHost(vmPodUID types.UID, volume string, isBlock bool) string {
+	return filepath.Join("/var/lib/kubelet/pods", string(vmPodUID), "volumes/kubernetes.io~empty-dir", getHotplugVolumeDiskPath(volume, isBlock))
+}
+
+func getHotplugVolumeDiskPath(volume string, isBlock bool) string {
+	if isBlock {
+		return filepath.Join("/hotplug-volumes", volume)
+	}
+	return filepath.Join("/hotplug-volumes", fmt.Sprintf("%s.img", volume))
+}
+
+// syncDisk copies the content of source to target chunk by chunk, skipping chunks that are already identical. This
+// keeps sparse targets sparse and makes repeated passes cheap.
+func syncDisk(ctx context.Context, source string, target string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	srcSize, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	dstSize, err := dst.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if dstSize < srcSize {
+		return fmt.Errorf("target disk size %d is smaller than source disk size %d", dstSize, srcSize)
+	}
+
+	srcBuf := make([]byte, volumeMoveChunkSize)
+	dstBuf := make([]byte, volumeMoveChunkSize)
+	for offset := int64(0); offset < srcSize; offset += volumeMoveChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := src.ReadAt(srcBuf, offset)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read source disk: %s", err)
+		}
+		if _, err := dst.ReadAt(dstBuf[:n], offset); err != nil && err != io.EOF {
+			return fmt.Errorf("read target disk: %s", err)
+		}
+		if bytes.Equal(srcBuf[:n], dstBuf[:n]) {
+			continue
+		}
+		if _, err := dst.WriteAt(srcBuf[:n], offset); err != nil {
+			return fmt.Errorf("write target disk: %s", err)
+		}
+	}
+	return dst.Sync()
+}
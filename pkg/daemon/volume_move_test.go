@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncDisk(t *testing.T) {
+	data := make([]byte, 2*volumeMoveChunkSize+4096)
+	rand.New(rand.NewSource(0)).Read(data)
+
+	tests := []struct {
+		source      []byte
+		target      []byte
+		expectedErr bool
+	}{{
+		source: data,
+		target: make([]byte, len(data)),
+	}, {
+		source: data,
+		target: func() []byte {
+			target := append([]byte{}, data...)
+			target[volumeMoveChunkSize+1] ^= 0xff
+			target[len(target)-1] ^= 0xff
+			return target
+		}(),
+	}, {
+		source: data,
+		target: make([]byte, len(data)+volumeMoveChunkSize),
+	}, {
+		source:      data,
+		target:      make([]byte, len(data)-1),
+		expectedErr: true,
+	}}
+
+	for i, tc := range tests {
+		dir := t.TempDir()
+		source := filepath.Join(dir, "source")
+		target := filepath.Join(dir, "target")
+		assert.NoError(t, os.WriteFile(source, tc.source, 0644), "case %d", i)
+		assert.NoError(t, os.WriteFile(target, tc.target, 0644), "case %d", i)
+
+		err := syncDisk(context.Background(), source, target)
+		if tc.expectedErr {
+			assert.Error(t, err, "case %d", i)
+			continue
+		}
+		assert.NoError(t, err, "case %d", i)
+
+		actual, err := os.ReadFile(target)
+		assert.NoError(t, err, "case %d", i)
+		assert.Equal(t, len(tc.target), len(actual), "case %d", i)
+		assert.Equal(t, tc.source, actual[:len(tc.source)], "case %d", i)
+	}
+}
+
+func TestSyncDiskCanceled(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target")
+	assert.NoError(t, os.WriteFile(source, []byte("data"), 0644))
+	assert.NoError(t, os.WriteFile(target, []byte("xxxx"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, syncDisk(ctx, source, target), context.Canceled)
+
+	actual, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("xxxx"), actual)
+}
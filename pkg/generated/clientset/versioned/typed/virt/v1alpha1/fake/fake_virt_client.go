@@ -20,6 +20,14 @@ func (c *FakeVirtV1alpha1) VirtualMachineMigrations(namespace string) v1alpha1.V
 	return &FakeVirtualMachineMigrations{c, namespace}
 }
 
+func (c *FakeVirtV1alpha1) VirtualMachineVolumeMoves(namespace string) v1alpha1.VirtualMachineVolumeMoveInterface {
+	return &FakeVirtualMachineVolumeMoves{c, namespace}
+}
+
+func (c *FakeVirtV1alpha1) VolumeImports(namespace string) v1alpha1.VolumeImportInterface {
+	return &FakeVolumeImports{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeVirtV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVirtualMachineVolumeMoves implements VirtualMachineVolumeMoveInterface
+type FakeVirtualMachineVolumeMoves struct {
+	Fake *FakeVirtV1alpha1
+	ns   string
+}
+
+var virtualmachinevolumemovesResource = schema.GroupVersionResource{Group: "virt.virtink.smartx.com", Version: "v1alpha1", Resource: "virtualmachinevolumemoves"}
+
+var virtualmachinevolumemovesKind = schema.GroupVersionKind{Group: "virt.virtink.smartx.com", Version: "v1alpha1", Kind: "VirtualMachineVolumeMove"}
+
+// Get takes name of the virtualMachineVolumeMove, and returns the corresponding virtualMachineVolumeMove object, and an error if there is any.
+func (c *FakeVirtualMachineVolumeMoves) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(virtualmachinevolumemovesResource, c.ns, name), &v1alpha1.VirtualMachineVolumeMove{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), err
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineVolumeMoves that match those selectors.
+func (c *FakeVirtualMachineVolumeMoves) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VirtualMachineVolumeMoveList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(virtualmachinevolumemovesResource, virtualmachinevolumemovesKind, c.ns, opts), &v1alpha1.VirtualMachineVolumeMoveList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VirtualMachineVolumeMoveList{ListMeta: obj.(*v1alpha1.VirtualMachineVolumeMoveList).ListMeta}
+	for _, item := range obj.(*v1alpha1.VirtualMachineVolumeMoveList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineVolumeMoves.
+func (c *FakeVirtualMachineVolumeMoves) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(virtualmachinevolumemovesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a virtualMachineVolumeMove and creates it.  Returns the server's representation of the virtualMachineVolumeMove, and an error, if there is any.
+func (c *FakeVirtualMachineVolumeMoves) Create(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.CreateOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(virtualmachinevolumemovesResource, c.ns, virtualMachineVolumeMove), &v1alpha1.VirtualMachineVolumeMove{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), err
+}
+
+// Update takes the representation of a virtualMachineVolumeMove and updates it. Returns the server's representation of the virtualMachineVolumeMove, and an error, if there is any.
+func (c *FakeVirtualMachineVolumeMoves) Update(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(virtualmachinevolumemovesResource, c.ns, virtualMachineVolumeMove), &v1alpha1.VirtualMachineVolumeMove{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVirtualMachineVolumeMoves) UpdateStatus(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (*v1alpha1.VirtualMachineVolumeMove, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(virtualmachinevolumemovesResource, "status", c.ns, virtualMachineVolumeMove), &v1alpha1.VirtualMachineVolumeMove{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), err
+}
+
+// Delete takes name of the virtualMachineVolumeMove and deletes it. Returns an error if one occurs.
+func (c *FakeVirtualMachineVolumeMoves) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(virtualmachinevolumemovesResource, c.ns, name, opts), &v1alpha1.VirtualMachineVolumeMove{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVirtualMachineVolumeMoves) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(virtualmachinevolumemovesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VirtualMachineVolumeMoveList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched virtualMachineVolumeMove.
+func (c *FakeVirtualMachineVolumeMoves) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(virtualmachinevolumemovesResource, c.ns, name, pt, data, subresources...), &v1alpha1.VirtualMachineVolumeMove{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), err
+}
@@ -0,0 +1,126 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVolumeImports implements VolumeImportInterface
+type FakeVolumeImports struct {
+	Fake *FakeVirtV1alpha1
+	ns   string
+}
+
+var volumeimportsResource = schema.GroupVersionResource{Group: "virt.virtink.smartx.com", Version: "v1alpha1", Resource: "volumeimports"}
+
+var volumeimportsKind = schema.GroupVersionKind{Group: "virt.virtink.smartx.com", Version: "v1alpha1", Kind: "VolumeImport"}
+
+// Get takes name of the volumeImport, and returns the corresponding volumeImport object, and an error if there is any.
+func (c *FakeVolumeImports) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VolumeImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(volumeimportsResource, c.ns, name), &v1alpha1.VolumeImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeImport), err
+}
+
+// List takes label and field selectors, and returns the list of VolumeImports that match those selectors.
+func (c *FakeVolumeImports) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VolumeImportList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(volumeimportsResource, volumeimportsKind, c.ns, opts), &v1alpha1.VolumeImportList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VolumeImportList{ListMeta: obj.(*v1alpha1.VolumeImportList).ListMeta}
+	for _, item := range obj.(*v1alpha1.VolumeImportList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested volumeImports.
+func (c *FakeVolumeImports) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(volumeimportsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a volumeImport and creates it.  Returns the server's representation of the volumeImport, and an error, if there is any.
+func (c *FakeVolumeImports) Create(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.CreateOptions) (result *v1alpha1.VolumeImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(volumeimportsResource, c.ns, volumeImport), &v1alpha1.VolumeImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeImport), err
+}
+
+// Update takes the representation of a volumeImport and updates it. Returns the server's representation of the volumeImport, and an error, if there is any.
+func (c *FakeVolumeImports) Update(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (result *v1alpha1.VolumeImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(volumeimportsResource, c.ns, volumeImport), &v1alpha1.VolumeImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeImport), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVolumeImports) UpdateStatus(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (*v1alpha1.VolumeImport, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(volumeimportsResource, "status", c.ns, volumeImport), &v1alpha1.VolumeImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeImport), err
+}
+
+// Delete takes name of the volumeImport and deletes it. Returns an error if one occurs.
+func (c *FakeVolumeImports) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(volumeimportsResource, c.ns, name, opts), &v1alpha1.VolumeImport{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVolumeImports) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(volumeimportsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VolumeImportList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched volumeImport.
+func (c *FakeVolumeImports) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeImport, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(volumeimportsResource, c.ns, name, pt, data, subresources...), &v1alpha1.VolumeImport{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeImport), err
+}
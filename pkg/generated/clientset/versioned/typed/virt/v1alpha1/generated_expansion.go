@@ -5,3 +5,7 @@ package v1alpha1
 type VirtualMachineExpansion interface{}
 
 type VirtualMachineMigrationExpansion interface{}
+
+type VirtualMachineVolumeMoveExpansion interface{}
+
+type VolumeImportExpansion interface{}
@@ -14,6 +14,8 @@ type VirtV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	VirtualMachinesGetter
 	VirtualMachineMigrationsGetter
+	VirtualMachineVolumeMovesGetter
+	VolumeImportsGetter
 }
 
 // VirtV1alpha1Client is used to interact with features provided by the virt.virtink.smartx.com group.
@@ -29,6 +31,14 @@ func (c *VirtV1alpha1Client) VirtualMachineMigrations(namespace string) VirtualM
 	return newVirtualMachineMigrations(c, namespace)
 }
 
+func (c *VirtV1alpha1Client) VirtualMachineVolumeMoves(namespace string) VirtualMachineVolumeMoveInterface {
+	return newVirtualMachineVolumeMoves(c, namespace)
+}
+
+func (c *VirtV1alpha1Client) VolumeImports(namespace string) VolumeImportInterface {
+	return newVolumeImports(c, namespace)
+}
+
 // NewForConfig creates a new VirtV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
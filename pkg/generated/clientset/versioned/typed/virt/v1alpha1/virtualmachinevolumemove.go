@@ -0,0 +1,179 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	scheme "github.com/smartxworks/virtink/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VirtualMachineVolumeMovesGetter has a method to return a VirtualMachineVolumeMoveInterface.
+// A group's client should implement this interface.
+type VirtualMachineVolumeMovesGetter interface {
+	VirtualMachineVolumeMoves(namespace string) VirtualMachineVolumeMoveInterface
+}
+
+// VirtualMachineVolumeMoveInterface has methods to work with VirtualMachineVolumeMove resources.
+type VirtualMachineVolumeMoveInterface interface {
+	Create(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.CreateOptions) (*v1alpha1.VirtualMachineVolumeMove, error)
+	Update(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (*v1alpha1.VirtualMachineVolumeMove, error)
+	UpdateStatus(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (*v1alpha1.VirtualMachineVolumeMove, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.VirtualMachineVolumeMove, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.VirtualMachineVolumeMoveList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VirtualMachineVolumeMove, err error)
+	VirtualMachineVolumeMoveExpansion
+}
+
+// virtualMachineVolumeMoves implements VirtualMachineVolumeMoveInterface
+type virtualMachineVolumeMoves struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVirtualMachineVolumeMoves returns a VirtualMachineVolumeMoves
+func newVirtualMachineVolumeMoves(c *VirtV1alpha1Client, namespace string) *virtualMachineVolumeMoves {
+	return &virtualMachineVolumeMoves{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the virtualMachineVolumeMove, and returns the corresponding virtualMachineVolumeMove object, and an error if there is any.
+func (c *virtualMachineVolumeMoves) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	result = &v1alpha1.VirtualMachineVolumeMove{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VirtualMachineVolumeMoves that match those selectors.
+func (c *virtualMachineVolumeMoves) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VirtualMachineVolumeMoveList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.VirtualMachineVolumeMoveList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested virtualMachineVolumeMoves.
+func (c *virtualMachineVolumeMoves) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a virtualMachineVolumeMove and creates it.  Returns the server's representation of the virtualMachineVolumeMove, and an error, if there is any.
+func (c *virtualMachineVolumeMoves) Create(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.CreateOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	result = &v1alpha1.VirtualMachineVolumeMove{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineVolumeMove).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a virtualMachineVolumeMove and updates it. Returns the server's representation of the virtualMachineVolumeMove, and an error, if there is any.
+func (c *virtualMachineVolumeMoves) Update(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	result = &v1alpha1.VirtualMachineVolumeMove{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		Name(virtualMachineVolumeMove.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineVolumeMove).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *virtualMachineVolumeMoves) UpdateStatus(ctx context.Context, virtualMachineVolumeMove *v1alpha1.VirtualMachineVolumeMove, opts v1.UpdateOptions) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	result = &v1alpha1.VirtualMachineVolumeMove{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		Name(virtualMachineVolumeMove.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(virtualMachineVolumeMove).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the virtualMachineVolumeMove and deletes it. Returns an error if one occurs.
+func (c *virtualMachineVolumeMoves) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *virtualMachineVolumeMoves) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched virtualMachineVolumeMove.
+func (c *virtualMachineVolumeMoves) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VirtualMachineVolumeMove, err error) {
+	result = &v1alpha1.VirtualMachineVolumeMove{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("virtualmachinevolumemoves").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
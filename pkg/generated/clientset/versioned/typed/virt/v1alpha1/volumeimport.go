@@ -0,0 +1,179 @@
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	scheme "github.com/smartxworks/virtink/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VolumeImportsGetter has a method to return a VolumeImportInterface.
+// A group's client should implement this interface.
+type VolumeImportsGetter interface {
+	VolumeImports(namespace string) VolumeImportInterface
+}
+
+// VolumeImportInterface has methods to work with VolumeImport resources.
+type VolumeImportInterface interface {
+	Create(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.CreateOptions) (*v1alpha1.VolumeImport, error)
+	Update(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (*v1alpha1.VolumeImport, error)
+	UpdateStatus(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (*v1alpha1.VolumeImport, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.VolumeImport, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.VolumeImportList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeImport, err error)
+	VolumeImportExpansion
+}
+
+// volumeImports implements VolumeImportInterface
+type volumeImports struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVolumeImports returns a VolumeImports
+func newVolumeImports(c *VirtV1alpha1Client, namespace string) *volumeImports {
+	return &volumeImports{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the volumeImport, and returns the corresponding volumeImport object, and an error if there is any.
+func (c *volumeImports) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VolumeImport, err error) {
+	result = &v1alpha1.VolumeImport{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VolumeImports that match those selectors.
+func (c *volumeImports) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VolumeImportList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.VolumeImportList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested volumeImports.
+func (c *volumeImports) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a volumeImport and creates it.  Returns the server's representation of the volumeImport, and an error, if there is any.
+func (c *volumeImports) Create(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.CreateOptions) (result *v1alpha1.VolumeImport, err error) {
+	result = &v1alpha1.VolumeImport{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeImport).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a volumeImport and updates it. Returns the server's representation of the volumeImport, and an error, if there is any.
+func (c *volumeImports) Update(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (result *v1alpha1.VolumeImport, err error) {
+	result = &v1alpha1.VolumeImport{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		Name(volumeImport.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeImport).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *volumeImports) UpdateStatus(ctx context.Context, volumeImport *v1alpha1.VolumeImport, opts v1.UpdateOptions) (result *v1alpha1.VolumeImport, err error) {
+	result = &v1alpha1.VolumeImport{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		Name(volumeImport.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeImport).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the volumeImport and deletes it. Returns an error if one occurs.
+func (c *volumeImports) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *volumeImports) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("volumeimports").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched volumeImport.
+func (c *volumeImports) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeImport, err error) {
+	result = &v1alpha1.VolumeImport{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("volumeimports").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -41,6 +41,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Virt().V1alpha1().VirtualMachines().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("virtualmachinemigrations"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Virt().V1alpha1().VirtualMachineMigrations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("virtualmachinevolumemoves"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Virt().V1alpha1().VirtualMachineVolumeMoves().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("volumeimports"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Virt().V1alpha1().VolumeImports().Informer()}, nil
 
 	}
 
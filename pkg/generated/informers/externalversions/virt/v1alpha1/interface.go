@@ -12,6 +12,10 @@ type Interface interface {
 	VirtualMachines() VirtualMachineInformer
 	// VirtualMachineMigrations returns a VirtualMachineMigrationInformer.
 	VirtualMachineMigrations() VirtualMachineMigrationInformer
+	// VirtualMachineVolumeMoves returns a VirtualMachineVolumeMoveInformer.
+	VirtualMachineVolumeMoves() VirtualMachineVolumeMoveInformer
+	// VolumeImports returns a VolumeImportInformer.
+	VolumeImports() VolumeImportInformer
 }
 
 type version struct {
@@ -34,3 +38,13 @@ func (v *version) VirtualMachines() VirtualMachineInformer {
 func (v *version) VirtualMachineMigrations() VirtualMachineMigrationInformer {
 	return &virtualMachineMigrationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// VirtualMachineVolumeMoves returns a VirtualMachineVolumeMoveInformer.
+func (v *version) VirtualMachineVolumeMoves() VirtualMachineVolumeMoveInformer {
+	return &virtualMachineVolumeMoveInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// VolumeImports returns a VolumeImportInformer.
+func (v *version) VolumeImports() VolumeImportInformer {
+	return &volumeImportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
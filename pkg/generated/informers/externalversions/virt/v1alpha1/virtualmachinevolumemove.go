@@ -0,0 +1,74 @@
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	time "time"
+
+	virtv1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	versioned "github.com/smartxworks/virtink/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/smartxworks/virtink/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/smartxworks/virtink/pkg/generated/listers/virt/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VirtualMachineVolumeMoveInformer provides access to a shared informer and lister for
+// VirtualMachineVolumeMoves.
+type VirtualMachineVolumeMoveInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.VirtualMachineVolumeMoveLister
+}
+
+type virtualMachineVolumeMoveInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewVirtualMachineVolumeMoveInformer constructs a new informer for VirtualMachineVolumeMove type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVirtualMachineVolumeMoveInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredVirtualMachineVolumeMoveInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredVirtualMachineVolumeMoveInformer constructs a new informer for VirtualMachineVolumeMove type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredVirtualMachineVolumeMoveInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VirtV1alpha1().VirtualMachineVolumeMoves(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.VirtV1alpha1().VirtualMachineVolumeMoves(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&virtv1alpha1.VirtualMachineVolumeMove{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *virtualMachineVolumeMoveInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredVirtualMachineVolumeMoveInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *virtualMachineVolumeMoveInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&virtv1alpha1.VirtualMachineVolumeMove{}, f.defaultInformer)
+}
+
+func (f *virtualMachineVolumeMoveInformer) Lister() v1alpha1.VirtualMachineVolumeMoveLister {
+	return v1alpha1.NewVirtualMachineVolumeMoveLister(f.Informer().GetIndexer())
+}
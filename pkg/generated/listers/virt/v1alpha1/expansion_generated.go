@@ -17,3 +17,19 @@ type VirtualMachineMigrationListerExpansion interface{}
 // VirtualMachineMigrationNamespaceListerExpansion allows custom methods to be added to
 // VirtualMachineMigrationNamespaceLister.
 type VirtualMachineMigrationNamespaceListerExpansion interface{}
+
+// VirtualMachineVolumeMoveListerExpansion allows custom methods to be added to
+// VirtualMachineVolumeMoveLister.
+type VirtualMachineVolumeMoveListerExpansion interface{}
+
+// VirtualMachineVolumeMoveNamespaceListerExpansion allows custom methods to be added to
+// VirtualMachineVolumeMoveNamespaceLister.
+type VirtualMachineVolumeMoveNamespaceListerExpansion interface{}
+
+// VolumeImportListerExpansion allows custom methods to be added to
+// VolumeImportLister.
+type VolumeImportListerExpansion interface{}
+
+// VolumeImportNamespaceListerExpansion allows custom methods to be added to
+// VolumeImportNamespaceLister.
+type VolumeImportNamespaceListerExpansion interface{}
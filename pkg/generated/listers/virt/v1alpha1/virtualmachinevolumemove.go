@@ -0,0 +1,83 @@
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/smartxworks/virtink/pkg/apis/virt/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// VirtualMachineVolumeMoveLister helps list VirtualMachineVolumeMoves.
+// All objects returned here must be treated as read-only.
+type VirtualMachineVolumeMoveLister interface {
+	// List lists all VirtualMachineVolumeMoves in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.VirtualMachineVolumeMove, err error)
+	// VirtualMachineVolumeMoves returns an object that can list and get VirtualMachineVolumeMoves.
+	VirtualMachineVolumeMoves(namespace string) VirtualMachineVolumeMoveNamespaceLister
+	VirtualMachineVolumeMoveListerExpansion
+}
+
+// virtualMachineVolumeMoveLister implements the VirtualMachineVolumeMoveLister interface.
+type virtualMachineVolumeMoveLister struct {
+	indexer cache.Indexer
+}
+
+// NewVirtualMachineVolumeMoveLister returns a new VirtualMachineVolumeMoveLister.
+func NewVirtualMachineVolumeMoveLister(indexer cache.Indexer) VirtualMachineVolumeMoveLister {
+	return &virtualMachineVolumeMoveLister{indexer: indexer}
+}
+
+// List lists all VirtualMachineVolumeMoves in the indexer.
+func (s *virtualMachineVolumeMoveLister) List(selector labels.Selector) (ret []*v1alpha1.VirtualMachineVolumeMove, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VirtualMachineVolumeMove))
+	})
+	return ret, err
+}
+
+// VirtualMachineVolumeMoves returns an object that can list and get VirtualMachineVolumeMoves.
+func (s *virtualMachineVolumeMoveLister) VirtualMachineVolumeMoves(namespace string) VirtualMachineVolumeMoveNamespaceLister {
+	return virtualMachineVolumeMoveNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// VirtualMachineVolumeMoveNamespaceLister helps list and get VirtualMachineVolumeMoves.
+// All objects returned here must be treated as read-only.
+type VirtualMachineVolumeMoveNamespaceLister interface {
+	// List lists all VirtualMachineVolumeMoves in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.VirtualMachineVolumeMove, err error)
+	// Get retrieves the VirtualMachineVolumeMove from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.VirtualMachineVolumeMove, error)
+	VirtualMachineVolumeMoveNamespaceListerExpansion
+}
+
+// virtualMachineVolumeMoveNamespaceLister implements the VirtualMachineVolumeMoveNamespaceLister
+// interface.
+type virtualMachineVolumeMoveNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all VirtualMachineVolumeMoves in the indexer for a given namespace.
+func (s virtualMachineVolumeMoveNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.VirtualMachineVolumeMove, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VirtualMachineVolumeMove))
+	})
+	return ret, err
+}
+
+// Get retrieves the VirtualMachineVolumeMove from the indexer for a given namespace and name.
+func (s virtualMachineVolumeMoveNamespaceLister) Get(name string) (*v1alpha1.VirtualMachineVolumeMove, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("virtualmachinevolumemove"), name)
+	}
+	return obj.(*v1alpha1.VirtualMachineVolumeMove), nil
+}
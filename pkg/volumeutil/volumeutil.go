@@ -3,9 +3,12 @@ package volumeutil
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	cdiv1beta1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -24,6 +27,21 @@ func IsBlock(ctx context.Context, c client.Client, namespace string, volume virt
 	return pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock, nil
 }
 
+func GetCapacity(ctx context.Context, c client.Client, namespace string, volume virtv1alpha1.Volume) (*resource.Quantity, error) {
+	pvc, err := getPVC(ctx, c, namespace, volume)
+	if err != nil {
+		return nil, err
+	}
+	if pvc == nil {
+		return nil, errors.New("pvc not found")
+	}
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if !ok {
+		return nil, nil
+	}
+	return &capacity, nil
+}
+
 func IsReady(ctx context.Context, c client.Client, namespace string, volume virtv1alpha1.Volume) (bool, error) {
 	if volume.DataVolume == nil {
 		return true, nil
@@ -75,3 +93,13 @@ func getPVC(ctx context.Context, c client.Client, namespace string, volume virtv
 	}
 	return &pvc, nil
 }
+
+// GetDiskSize returns the size of a disk image file or a block device, the latter of which is always 0 in stat.
+func GetDiskSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.Seek(0, io.SeekEnd)
+}